@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/holygun/go-trainer/trainer"
+)
+
+// runAdvise режим "trainer advise": рекомендация ставок на следующее событие
+// по сохраненной сессии и применение результата сыгранного события
+func runAdvise(args []string) {
+	fs := flag.NewFlagSet("advise", flag.ExitOnError)
+	var (
+		sessionFile  = fs.String("session", "session.json", "Файл сессии")
+		initFile     = fs.String("init", "", "Создать сессию по истории из .input файла")
+		strategyName = fs.String("strategy", "xlDrop", "Имя стратегии (для -init)")
+		hockey       = fs.Bool("hockey", false, "События хоккея (для -init)")
+		oddsString   = fs.String("odds", "", "Коэффициенты следующего события: oddF,oddX,oddL")
+		result       = fs.String("result", "", "Результат события с рекомендованными ставками (F/X/L)")
+		debug        = fs.Bool("debug", false, "Подробный вывод")
+		params       paramFlags
+	)
+	fs.Var(&params, "param", "Параметр стратегии name=value (для -init, можно повторять)")
+	// Конфигурация и банк нужны только при создании сессии (-init)
+	config := addConfigFlags(fs)
+	logging := addLogFlags(fs, "warn")
+	fs.Parse(args)
+
+	bus, err := logging.bus(*debug, false)
+	if err != nil {
+		usageFatal(fs, err)
+	}
+
+	var session *trainer.Session
+	var nextOdds *trainer.Odds
+
+	if *initFile != "" {
+		strategy, err := trainer.GetStrategy(*strategyName)
+		if err != nil {
+			usageFatal(fs, err)
+		}
+		events, err := trainer.ReadInputFile(*initFile)
+		if err != nil {
+			log.Fatalf("Ошибка чтения файла %s: %v", *initFile, err)
+		}
+
+		cfg := config.load(fs)
+		if err := applyParams(&cfg, strategy, params); err != nil {
+			usageFatal(fs, err)
+		}
+
+		flags := trainer.Flags{Strategy: strategy.Name(), Hockey: *hockey, Debug: *debug, Config: &cfg, Bus: bus}
+		session = trainer.NewSession(strategy, flags, events)
+		fmt.Printf("📂 Сессия создана по %s: %d событий, стратегия %s\n",
+			*initFile, session.Last.EventNumber, session.Strategy)
+
+		// Несыгранное событие в конце истории - это событие, на которое нужен совет
+		if n := session.Last.EventNumber; n < len(events) && events[n].Result == "N" {
+			nextOdds = &trainer.Odds{OddF: events[n].OddF, OddX: events[n].OddX, OddL: events[n].OddL}
+		}
+	} else {
+		var err error
+		session, err = trainer.LoadSession(*sessionFile)
+		if err != nil {
+			log.Fatalf("Ошибка загрузки сессии: %v", err)
+		}
+		session.Bus = bus
+	}
+
+	if *result != "" {
+		record, err := session.Settle(*result)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("✅ Событие %d: %s, убытки: F=%.0f X=%.0f L=%.0f, Total=%.0f\n",
+			record.EventNumber, record.Result, record.LossF, record.LossX, record.LossL, record.Total)
+	}
+
+	if *oddsString != "" {
+		odds, err := parseOdds(*oddsString)
+		if err != nil {
+			usageFatal(fs, err)
+		}
+		nextOdds = &odds
+	}
+
+	if nextOdds != nil {
+		decision, err := session.Advise(*nextOdds)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("🎯 Событие %d (%s), коэффициенты F=%.2f X=%.2f L=%.2f\n",
+			session.Last.EventNumber+1, session.Strategy, nextOdds.OddF, nextOdds.OddX, nextOdds.OddL)
+		fmt.Printf("   betF: %.0f\n   betX: %.0f\n   betL: %.0f\n",
+			decision.Bets.F, decision.Bets.X, decision.Bets.L)
+		if decision.Limit != "" {
+			fmt.Printf("   ⚠️ Сработал лимит ставок: %s\n", decision.Limit)
+		}
+	}
+
+	if err := session.Save(*sessionFile); err != nil {
+		log.Fatalf("Ошибка сохранения сессии: %v", err)
+	}
+	fmt.Printf("💾 Сессия сохранена в %s\n", *sessionFile)
+}
+
+// parseOdds парсит строку коэффициентов вида "1.88,3.7,4.3"
+func parseOdds(s string) (trainer.Odds, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return trainer.Odds{}, fmt.Errorf("invalid odds %q: expected oddF,oddX,oddL", s)
+	}
+
+	values := make([]float64, 3)
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || value <= 1 {
+			return trainer.Odds{}, fmt.Errorf("invalid odd value %q in %q", part, s)
+		}
+		values[i] = value
+	}
+
+	return trainer.Odds{OddF: values[0], OddX: values[1], OddL: values[2]}, nil
+}
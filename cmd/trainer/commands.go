@@ -0,0 +1,160 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/holygun/go-trainer/trainer"
+)
+
+// Коды завершения: ошибки использования (неизвестная команда, флаги,
+// аргументы) отличаются от ошибок данных (файлы, конфигурация, расчет)
+const (
+	exitOK    = 0
+	exitData  = 1 // log.Fatal завершает работу с этим кодом
+	exitUsage = 2 // Как у flag.ExitOnError
+)
+
+// command подкоманда trainer
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
+}
+
+// commands подкоманды в порядке справки
+var commands = []command{
+	{"simulate", "Расчет стратегии по строке событий F/X/L (по умолчанию)", runSimulate},
+	{"report", "Отчет по ранее сохраненному CSV", runReport},
+	{"real", "Расчет .input файлов из папки real-games в .actual", runReal},
+	{"advise", "Ставки на следующее событие по сохраненной сессии", runAdvise},
+	{"strategies", "Список стратегий и схема их параметров", runStrategies},
+	{"validate", "Проверка .input, CSV и файлов конфигурации", runValidate},
+	{"montecarlo", "Расчет на случайных последовательностях событий", runMonteCarlo},
+	{"compare", "Сравнение стратегий на одних событиях", runCompare},
+	{"sweep", "Перебор параметров стратегии", runSweep},
+	{"explain", "Объяснение ставок события по широкому CSV", runExplain},
+	{"import", "Импорт исторических матчей football-data", runImport},
+	{"journal", "Сверка журнала ставок с расчетом стратегии", runJournal},
+	{"drift", "Расхождение пересчета с сохраненными .actual/.expected", runDrift},
+}
+
+func main() {
+	name, args := "simulate", os.Args[1:]
+	switch {
+	case len(args) == 0:
+	case args[0] == "help" || args[0] == "-h" || args[0] == "-help" || args[0] == "--help":
+		if len(args) > 1 {
+			if cmd, ok := findCommand(args[1]); ok {
+				cmd.run([]string{"-h"})
+				return
+			}
+		}
+		printUsage(os.Stdout)
+		return
+	case strings.HasPrefix(args[0], "-"):
+		// Прежняя форма вызова без подкоманды
+		name, args = legacyCommand(args)
+	default:
+		name, args = args[0], args[1:]
+	}
+
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Неизвестная команда %q\n\n", name)
+		printUsage(os.Stderr)
+		os.Exit(exitUsage)
+	}
+	cmd.run(args)
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// printUsage выводит список подкоманд
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Использование: trainer <команда> [флаги]")
+	fmt.Fprintln(w, "\nКоманды:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nФлаги команды: trainer <команда> -h")
+	fmt.Fprintln(w, "Прежние формы без команды работают как раньше: -report file.csv (report), -real (real), остальное - simulate")
+	fmt.Fprintf(w, "Коды завершения: %d - успех, %d - ошибка данных, %d - ошибка использования\n", exitOK, exitData, exitUsage)
+}
+
+// usageFatal сообщает об ошибке в аргументах команды и завершает работу
+// с кодом exitUsage
+func usageFatal(fs *flag.FlagSet, err error) {
+	fmt.Fprintf(fs.Output(), "trainer %s: %v\nФлаги команды: trainer %s -h\n", fs.Name(), err, fs.Name())
+	os.Exit(exitUsage)
+}
+
+// legacyCommand переводит прежнюю форму вызова без подкоманды в подкоманду:
+// -report имеет приоритет над -real, остальные вызовы - simulate. -real без
+// -hockey (и без -tags/-exclude-tags) пропускает хоккейные файлы, как раньше.
+func legacyCommand(args []string) (string, []string) {
+	if _, file, ok := takeFlag(args, "report", true); ok {
+		// Остальные флаги в режиме -report не действовали
+		return "report", []string{file}
+	}
+	if rest, _, ok := takeFlag(args, "real", false); ok {
+		// Стратегия и коэффициенты в режиме -real берутся из .input файлов,
+		// флаги расчета строки событий не действовали
+		for _, name := range []string{"input", "output", "strategy", "seed", "odds-from"} {
+			rest, _, _ = takeFlag(rest, name, true)
+		}
+		for _, name := range []string{"equity", "reactions"} {
+			rest, _, _ = takeFlag(rest, name, false)
+		}
+		// Прежний -real без -hockey обрабатывал только футбольные файлы
+		if !hasFlag(rest, "hockey", false) && !hasFlag(rest, "tags", true) && !hasFlag(rest, "exclude-tags", true) {
+			rest = append(rest, "-exclude-tags", trainer.TagHockey)
+		}
+		return "real", rest
+	}
+	return "simulate", args
+}
+
+// takeFlag убирает из аргументов все вхождения флага name (-name, --name,
+// -name=value, а для флагов со значением и "-name value") и возвращает
+// последнее значение. Булев флаг со значением false не считается заданным.
+func takeFlag(args []string, name string, hasValue bool) (rest []string, value string, found bool) {
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimPrefix(strings.TrimPrefix(args[i], "-"), "-")
+		if !strings.HasPrefix(args[i], "-") || (arg != name && !strings.HasPrefix(arg, name+"=")) {
+			rest = append(rest, args[i])
+			continue
+		}
+		_, inline, hasInline := strings.Cut(arg, "=")
+		switch {
+		case hasInline && hasValue:
+			value, found = inline, true
+		case hasInline:
+			enabled, err := strconv.ParseBool(inline)
+			found = err != nil || enabled
+		case hasValue && i+1 < len(args):
+			i++
+			value, found = args[i], true
+		default:
+			found = true
+		}
+	}
+	return rest, value, found
+}
+
+// hasFlag сообщает, задан ли в аргументах флаг name (см. takeFlag)
+func hasFlag(args []string, name string, hasValue bool) bool {
+	_, _, found := takeFlag(args, name, hasValue)
+	return found
+}
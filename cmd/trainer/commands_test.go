@@ -0,0 +1,161 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLegacyCommand checks how the old flag forms without a subcommand map to
+// subcommand arguments
+func TestLegacyCommand(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		cmd  string
+		want []string
+	}{
+		{"simulate by default", []string{"-input", "F/X/L", "-seed", "1"}, "simulate", []string{"-input", "F/X/L", "-seed", "1"}},
+		{"report drops other flags", []string{"-seed", "1", "-report", "out.csv", "-debug"}, "report", []string{"out.csv"}},
+		{"report inline value", []string{"--report=out.csv"}, "report", []string{"out.csv"}},
+		{"report wins over real", []string{"-real", "-report", "out.csv"}, "report", []string{"out.csv"}},
+		{"real keeps real flags", []string{"-real", "-hockey", "-debug"}, "real", []string{"-hockey", "-debug"}},
+		{"real drops simulate flags", []string{"-input", "F/X", "-real", "-strategy=xlDrop", "-seed", "3", "-equity", "-odds-from", "a.csv", "-output", "b.csv"}, "real", []string{"-exclude-tags", "hockey"}},
+		{"real without hockey skips hockey files", []string{"-real", "-debug"}, "real", []string{"-debug", "-exclude-tags", "hockey"}},
+		{"real with -hockey=false", []string{"-real", "-hockey=false"}, "real", []string{"-hockey=false", "-exclude-tags", "hockey"}},
+		{"real with explicit tags", []string{"-real", "-tags", "cup"}, "real", []string{"-tags", "cup"}},
+		{"real=false is simulate", []string{"-real=false", "-seed", "1"}, "simulate", []string{"-real=false", "-seed", "1"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, args := legacyCommand(tc.args)
+			assert.Equal(t, tc.cmd, cmd)
+			assert.Equal(t, tc.want, args)
+		})
+	}
+}
+
+// TestLegacyRealHockey checks that the legacy -real skips hockey files unless
+// -hockey is given, while "trainer real" processes every file
+func TestLegacyRealHockey(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "real-games"), 0755))
+	for _, name := range []string{"xldrop.input", "xldrop.meta", "xldrop-hockey.input", "xldrop-hockey.meta"} {
+		data, err := os.ReadFile(filepath.Join("../../real-games", name))
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "real-games", name), data, 0644))
+	}
+
+	output, code := runTrainer(t, dir, "-real", "-dry-run")
+	assert.Equal(t, exitOK, code, output)
+	assert.Contains(t, output, "Будет пересчитан: xldrop.input")
+	assert.NotContains(t, output, "xldrop-hockey.input (")
+
+	output, code = runTrainer(t, dir, "-real", "-hockey", "-dry-run")
+	assert.Equal(t, exitOK, code, output)
+	assert.Contains(t, output, "Будет пересчитан: xldrop-hockey.input")
+	assert.NotContains(t, output, "xldrop.input (")
+
+	output, code = runTrainer(t, dir, "real", "-dry-run")
+	assert.Equal(t, exitOK, code, output)
+	assert.Contains(t, output, "Будет пересчитан: xldrop.input")
+	assert.Contains(t, output, "Будет пересчитан: xldrop-hockey.input")
+}
+
+// TestTakeFlag checks removing a flag in all its forms and the last value winning
+func TestTakeFlag(t *testing.T) {
+	rest, value, found := takeFlag([]string{"-seed", "1", "pos", "--seed=2", "-seeds", "3"}, "seed", true)
+	assert.True(t, found)
+	assert.Equal(t, "2", value)
+	assert.Equal(t, []string{"pos", "-seeds", "3"}, rest)
+
+	// A value flag at the end without its value is still found
+	rest, value, found = takeFlag([]string{"-x", "-seed"}, "seed", true)
+	assert.True(t, found)
+	assert.Equal(t, "", value)
+	assert.Equal(t, []string{"-x"}, rest)
+
+	_, _, found = takeFlag([]string{"-real=false"}, "real", false)
+	assert.False(t, found)
+	_, _, found = takeFlag([]string{"-real=true"}, "real", false)
+	assert.True(t, found)
+	rest, _, found = takeFlag([]string{"real", "-debug"}, "real", false)
+	assert.False(t, found)
+	assert.Equal(t, []string{"real", "-debug"}, rest)
+}
+
+// TestExitCodes checks that usage errors exit with exitUsage and data errors
+// with exitData
+func TestExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"help"}, exitOK},
+		{[]string{"nope"}, exitUsage},
+		{[]string{"simulate", "-nope"}, exitUsage},
+		{[]string{"simulate", "-strategy", "nope"}, exitUsage},
+		{[]string{"validate"}, exitUsage},
+		{[]string{"-nope"}, exitUsage},
+		{[]string{"report", "missing.csv"}, exitData},
+		{[]string{"-report", "missing.csv"}, exitData},
+		{[]string{"validate", "missing.input"}, exitData},
+		{[]string{"simulate", "-config", "missing.yaml"}, exitData},
+	}
+	for _, tc := range cases {
+		output, code := runTrainer(t, dir, tc.args...)
+		assert.Equal(t, tc.code, code, "%v: %s", tc.args, output)
+	}
+}
+
+// TestAdviseInitConfig checks that "advise -init" takes the shared config and
+// bankroll flags and stores them in the session
+func TestAdviseInitConfig(t *testing.T) {
+	dir := t.TempDir()
+	input, err := filepath.Abs("../../real-games/xldrop.input")
+	assert.NoError(t, err)
+
+	output, code := runTrainer(t, dir, "advise", "-init", input, "-capital", "1000000", "-on-cap", "clamp")
+	assert.Equal(t, exitOK, code, output)
+	session, err := trainer.LoadSession(filepath.Join(dir, "session.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1000000.0, session.Config.Bankroll.Capital)
+	assert.Equal(t, trainer.OnCapClamp, session.Config.Bankroll.OnCap)
+
+	output, code = runTrainer(t, dir, "advise", "-init", input, "-on-cap", "nope")
+	assert.Equal(t, exitUsage, code, output)
+}
+
+// TestSharedConfigFlags checks that import and journal read -config and the
+// bankroll flags like the other subcommands
+func TestSharedConfigFlags(t *testing.T) {
+	dir := t.TempDir()
+	season := filepath.Join(dir, "E0.csv")
+	assert.NoError(t, os.WriteFile(season, []byte("Div,Date,HomeTeam,AwayTeam,FTR,B365H,B365D,B365A\n"+
+		"E0,10/08/19,West Ham,Man City,A,12,6.5,1.22\n"+
+		"E0,17/08/19,Arsenal,Burnley,H,1.3,5.5,11\n"), 0644))
+	book, err := filepath.Abs("../../new_line_0000")
+	assert.NoError(t, err)
+
+	cases := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"import", "-strategy", "xlDrop", "-capital", "100000", season}, exitOK},
+		{[]string{"import", "-strategy", "xlDrop", "-on-cap", "nope", season}, exitUsage},
+		{[]string{"import", "-strategy", "xlDrop", "-config", "missing.yaml", season}, exitData},
+		{[]string{"journal", book, "-max-bet", "50000"}, exitOK},
+		{[]string{"journal", book, "-on-cap", "nope"}, exitUsage},
+		{[]string{"journal", book, "-config", "missing.yaml"}, exitData},
+	}
+	for _, tc := range cases {
+		output, code := runTrainer(t, dir, tc.args...)
+		assert.Equal(t, tc.code, code, "%v: %s", tc.args, output)
+	}
+}
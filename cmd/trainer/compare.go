@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/holygun/go-trainer/trainer"
+)
+
+// runCompare режим "trainer compare": несколько стратегий на одних событиях
+// и коэффициентах со сводной таблицей и общим CSV
+func runCompare(args []string) {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	var (
+		strategyNames = fs.String("strategy", "xlDrop,xlWithSupport", "Стратегии через запятую")
+		inputString   = fs.String("input", "", "Строка событий F/X/L (новые слева)")
+		outputFile    = fs.String("output", "compare_output.csv", "Имя выходного CSV файла")
+		hockey        = fs.Bool("hockey", false, "События хоккея")
+		debug         = fs.Bool("debug", false, "Подробный вывод")
+		seed          = fs.Int64("seed", 0, "Seed генератора коэффициентов (по умолчанию случайный)")
+		oddsFrom      = fs.String("odds-from", "", "Взять коэффициенты из .input или CSV файла")
+		params        paramFlags
+	)
+	fs.Var(&params, "param", "Параметр name=value для всех стратегий с таким параметром или strategy.name=value (можно повторять)")
+	config := addConfigFlags(fs)
+	// Предупреждения паттернов каждой стратегии в общем выводе только мешают
+	logging := addLogFlags(fs, "error")
+	fs.Parse(args)
+
+	seedSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			seedSet = true
+		}
+	})
+	if !seedSet {
+		*seed = time.Now().UnixNano()
+	}
+
+	var strategies []trainer.Strategy
+	for _, name := range strings.Split(*strategyNames, ",") {
+		strategy, err := trainer.GetStrategy(strings.TrimSpace(name))
+		if err != nil {
+			usageFatal(fs, err)
+		}
+		strategies = append(strategies, strategy)
+	}
+	if len(strategies) < 2 {
+		usageFatal(fs, errors.New("Для сравнения нужно минимум две стратегии: -strategy a,b"))
+	}
+
+	cfg := config.load(fs)
+	if err := applyCompareParams(&cfg, strategies, params); err != nil {
+		usageFatal(fs, err)
+	}
+
+	bus, err := logging.bus(*debug, false)
+	if err != nil {
+		usageFatal(fs, err)
+	}
+	flags := trainer.Flags{Hockey: *hockey, Debug: *debug, Seed: *seed, Config: &cfg, Bus: bus}
+
+	input := *inputString
+	if input == "" && *hockey {
+		input = EVENTS_HOCKEY
+	} else if input == "" {
+		input = EVENTS
+	}
+	events := trainer.ParseEvents(input)
+	if len(events) == 0 {
+		usageFatal(fs, errors.New("Не найдено корректных событий F/X/L во входной строке"))
+	}
+	eventsFromOldest := trainer.ReverseSlice(events)
+
+	meta := map[string]string{"seed": fmt.Sprint(*seed), "strategy": *strategyNames}
+	for _, strategy := range strategies {
+		for key, value := range trainer.ParamsMeta(strategy, cfg) {
+			meta[key] = value
+		}
+	}
+
+	var provider trainer.OddsProvider = trainer.NewRandomOddsProvider(*seed, flags)
+	if *oddsFrom != "" {
+		fileProvider, err := newFileOddsProvider(*oddsFrom, provider, flags)
+		if err != nil {
+			log.Fatalf("Ошибка чтения коэффициентов из %s: %v", *oddsFrom, err)
+		}
+		provider = fileProvider
+		meta["odds_from"] = filepath.Base(*oddsFrom)
+	}
+	if *config.file != "" {
+		meta["config"] = filepath.Base(*config.file)
+	}
+
+	fmt.Printf("📊 Сравнение %d стратегий на %d событиях\n", len(strategies), len(events))
+	fmt.Printf("🎲 Seed: %d\n", *seed)
+
+	comparison := trainer.Compare(eventsFromOldest, provider, flags, strategies)
+	if err := trainer.SaveComparisonCSV(comparison, *outputFile, meta); err != nil {
+		log.Fatalf("Ошибка сохранения CSV: %v", err)
+	}
+	fmt.Printf("✅ Данные сохранены в %s\n", *outputFile)
+
+	trainer.PrintComparison(comparison)
+}
+
+// applyCompareParams применяет -param к сравниваемым стратегиям. Параметр
+// "strategy.name=value" относится к одной стратегии, "name=value" - ко всем
+// стратегиям, у которых есть такой параметр.
+func applyCompareParams(cfg *trainer.Config, strategies []trainer.Strategy, params paramFlags) error {
+	for _, param := range params {
+		name, value, err := trainer.ParseParam(param)
+		if err != nil {
+			return err
+		}
+
+		applied := false
+		for _, strategy := range strategies {
+			paramName := name
+			if prefix, rest, ok := strings.Cut(name, "."); ok {
+				if prefix != strategy.Name() {
+					continue
+				}
+				paramName = rest
+			} else if !hasParam(strategy, paramName) {
+				continue
+			}
+			cfg.SetParam(strategy.Name(), paramName, value)
+			applied = true
+		}
+		if !applied {
+			return fmt.Errorf("no compared strategy has parameter %q", name)
+		}
+	}
+	return cfg.Validate()
+}
+
+// hasParam сообщает, объявлен ли у стратегии параметр name
+func hasParam(strategy trainer.Strategy, name string) bool {
+	for _, param := range trainer.StrategyParams(strategy) {
+		if param.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCompareCommand checks the compare table and CSV, the shared config and
+// bankroll flags and the exit codes of usage and data errors
+func TestCompareCommand(t *testing.T) {
+	dir := t.TempDir()
+	output, code := runTrainer(t, dir, "compare", "-seed", "1", "-input", "F/X/L/L/X/F", "-output", "compare.csv")
+	assert.Equal(t, exitOK, code, output)
+	assert.Contains(t, output, "СРАВНЕНИЕ СТРАТЕГИЙ")
+	assert.Contains(t, output, "xlWithSupport")
+
+	data, err := os.ReadFile(filepath.Join(dir, "compare.csv"))
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 8)
+	assert.Contains(t, lines[0], "seed=1")
+	assert.True(t, strings.HasPrefix(lines[1], "event_number,result,oddF,oddX,oddL,xlDrop.betF,"))
+	assert.Contains(t, lines[1], "xlWithSupport.total")
+
+	// Bankroll flags from the shared config flags stop both strategies
+	output, code = runTrainer(t, dir, "compare", "-seed", "1", "-input", "F/X/L/L/X/F", "-capital", "1000")
+	assert.Equal(t, exitOK, code, output)
+	assert.Contains(t, output, "xlDrop: разорение на событии 1")
+	assert.Contains(t, output, "xlWithSupport: разорение на событии 1")
+
+	usage := [][]string{
+		{"compare", "-strategy", "xlDrop"},
+		{"compare", "-strategy", "xlDrop,nope"},
+		{"compare", "-param", "nope=1"},
+		{"compare", "-param", "ratio=2"},
+		{"compare", "-on-cap", "retry"},
+		{"compare", "-input", "abc"},
+	}
+	for _, args := range usage {
+		output, code := runTrainer(t, dir, args...)
+		assert.Equal(t, exitUsage, code, "%v: %s", args, output)
+	}
+	output, code = runTrainer(t, dir, "compare", "-config", "missing.yaml")
+	assert.Equal(t, exitData, code, output)
+}
+
+// TestApplyCompareParams checks that a bare parameter goes to every compared
+// strategy that has it and a prefixed one only to its strategy
+func TestApplyCompareParams(t *testing.T) {
+	drop, _ := trainer.GetStrategy("xlDrop")
+	support, _ := trainer.GetStrategy("xlWithSupport")
+	strategies := []trainer.Strategy{drop, support}
+
+	cfg := trainer.DefaultConfig()
+	assert.NoError(t, applyCompareParams(&cfg, strategies, paramFlags{"ratio=0.4", "xlDrop.deferX=3"}))
+	assert.Equal(t, 0.4, cfg.StrategyParams(drop)["ratio"])
+	assert.Equal(t, 0.4, cfg.StrategyParams(support)["ratio"])
+	assert.Equal(t, 3.0, cfg.StrategyParams(drop)["deferX"])
+
+	cfg = trainer.DefaultConfig()
+	assert.ErrorContains(t, applyCompareParams(&cfg, strategies, paramFlags{"xlWithSupport.deferX=3"}), "deferX")
+	cfg = trainer.DefaultConfig()
+	assert.ErrorContains(t, applyCompareParams(&cfg, strategies, paramFlags{"writeOff"}), "name=value")
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/holygun/go-trainer/trainer"
+)
+
+// runDrift режим "trainer drift": пересчитывает .input файлы и сравнивает
+// результат с сохраненными .actual (или .expected) файлами, ничего не записывая
+func runDrift(args []string) {
+	fs := flag.NewFlagSet("drift", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Использование: trainer drift [флаги] [папка или .input файл...] (по умолчанию real-games)\n")
+		fs.PrintDefaults()
+	}
+	var (
+		format = fs.String("format", "console", "Формат отчета: console или json")
+		check  = fs.Bool("check", false, "Завершиться с кодом 1, если найдены расхождения")
+		params paramFlags
+	)
+	fs.Var(&params, "param", "Параметр стратегии файла name=value (можно повторять; сильнее параметров из .meta и сохраненного файла)")
+	config := addConfigFlags(fs)
+	fs.Parse(args)
+	if *format != "console" && *format != "json" {
+		usageFatal(fs, fmt.Errorf("unknown format %q (expected console or json)", *format))
+	}
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"real-games"}
+	}
+
+	cfg := config.load(fs)
+	overrides := trainer.Params{}
+	for _, param := range params {
+		name, value, err := trainer.ParseParam(param)
+		if err != nil {
+			usageFatal(fs, err)
+		}
+		overrides[name] = value
+	}
+	inputFiles, err := driftInputFiles(paths)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(inputFiles) == 0 {
+		log.Fatalf("no .input files in %v", paths)
+	}
+
+	drifts := make([]trainer.Drift, 0, len(inputFiles))
+	failed, changed := 0, 0
+	for _, inputFile := range inputFiles {
+		drift := driftFile(inputFile, cfg, overrides)
+		if drift.Error != "" {
+			failed++
+		} else if drift.HasDrift() {
+			changed++
+		}
+		drifts = append(drifts, drift)
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(drifts); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		trainer.PrintDrift(drifts)
+		fmt.Printf("\nФайлов: %d, с расхождениями: %d, с ошибками: %d\n", len(drifts), changed, failed)
+	}
+	if failed > 0 || (*check && changed > 0) {
+		os.Exit(exitData)
+	}
+}
+
+// driftInputFiles раскрывает папки в список .input файлов
+func driftInputFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(path, "*.input"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// driftFile пересчитывает один .input файл с параметрами сохраненного файла
+// и params и сравнивает результат с сохраненным
+func driftFile(inputFile string, cfg trainer.Config, params trainer.Params) trainer.Drift {
+	stored := trainer.StoredResultsPath(inputFile)
+	if stored == "" {
+		return trainer.Drift{Input: inputFile, Error: "нет .actual или .expected"}
+	}
+	records, strategy, err := trainer.RecomputeRealGame(inputFile, cfg, params)
+	if err != nil {
+		return trainer.Drift{Input: inputFile, Stored: stored, Error: err.Error()}
+	}
+	storedRecords, err := trainer.ReadCSV(stored)
+	if err != nil {
+		return trainer.Drift{Input: inputFile, Stored: stored, Strategy: strategy.Name(), Error: err.Error()}
+	}
+
+	drift := trainer.CompareRecords(storedRecords, records)
+	drift.Input, drift.Stored, drift.Strategy = inputFile, stored, strategy.Name()
+	return drift
+}
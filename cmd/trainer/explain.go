@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/holygun/go-trainer/trainer"
+)
+
+// runExplain режим "trainer explain run.csv -event 123": словами объясняет,
+// как получены ставки события по записи широкого CSV (-wide)
+func runExplain(args []string) {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	var (
+		eventNumber  = fs.Int("event", 0, "Номер события")
+		strategyName = fs.String("strategy", "", "Стратегия для описаний (по умолчанию из строки параметров CSV)")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Использование: trainer explain <файл.csv> -event N\n")
+		fs.PrintDefaults()
+	}
+
+	// Файл может стоять как до флагов, так и после них
+	filename := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		filename, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if filename == "" && fs.NArg() > 0 {
+		filename = fs.Arg(0)
+	}
+	if filename == "" || *eventNumber <= 0 {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	records, err := trainer.ReadCSV(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	name := *strategyName
+	if name == "" {
+		meta, err := trainer.ReadCSVMeta(filename)
+		if err != nil {
+			log.Fatalf("Ошибка чтения %s: %v", filename, err)
+		}
+		name = meta["strategy"]
+	}
+	var strategy trainer.Strategy
+	if name != "" {
+		strategy, err = trainer.GetStrategy(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	lines, err := trainer.Explain(records, *eventNumber, strategy)
+	if err != nil {
+		log.Fatalf("%s: %v", filename, err)
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
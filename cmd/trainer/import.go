@@ -0,0 +1,135 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/holygun/go-trainer/common"
+	"github.com/holygun/go-trainer/trainer"
+)
+
+// runImport режим "trainer import": исторические матчи из CSV football-data
+// в .input файл или сразу в расчет стратегии
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	var (
+		bookmaker    = fs.String("bookmaker", trainer.DefaultFootballDataBookmaker, "Букмекер: префикс колонок коэффициентов (B365, PS, WH, ...), Avg или Max")
+		leagues      = fs.String("league", "", "Лиги через запятую (колонка Div, например E0,SP1)")
+		teams        = fs.String("team", "", "Команды через запятую (матчи дома и в гостях)")
+		from         = fs.String("from", "", "Первая дата YYYY-MM-DD")
+		to           = fs.String("to", "", "Последняя дата YYYY-MM-DD")
+		output       = fs.String("output", "", "Записать события в .input файл")
+		strategyName = fs.String("strategy", "", "Сразу рассчитать события стратегией")
+		csvOutput    = fs.String("csv", "", "CSV файл с результатами расчета (с -strategy)")
+		seed         = fs.Int64("seed", 0, "Seed генератора коэффициентов (с -strategy)")
+		params       paramFlags
+	)
+	fs.Var(&params, "param", "Параметр стратегии name=value (с -strategy, можно повторять)")
+	// Конфигурация и банк нужны только для расчета (-strategy)
+	config := addConfigFlags(fs)
+	logging := addLogFlags(fs, "warn")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Использование: trainer import [флаги] <файл.csv>...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 || (*output == "" && *strategyName == "") {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	opts := trainer.FootballDataOptions{
+		Bookmaker: *bookmaker,
+		Leagues:   splitList(*leagues),
+		Teams:     splitList(*teams),
+	}
+	var err error
+	if opts.From, err = parseDate(*from); err != nil {
+		usageFatal(fs, err)
+	}
+	if opts.To, err = parseDate(*to); err != nil {
+		usageFatal(fs, err)
+	}
+
+	imported, err := trainer.ImportFootballData(fs.Args(), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(imported.Events) == 0 {
+		log.Fatalf("Нет матчей, подходящих под фильтры (пропущено без результата или коэффициентов: %d)", imported.Skipped)
+	}
+	fmt.Printf("📥 Импортировано матчей: %d (с %s по %s), пропущено без результата или коэффициентов: %d\n",
+		len(imported.Events), imported.Events[0].Match.Date, imported.Events[len(imported.Events)-1].Match.Date, imported.Skipped)
+
+	if *output != "" {
+		if err := common.WriteInputFile(*output, imported.Events); err != nil {
+			log.Fatalf("Ошибка записи %s: %v", *output, err)
+		}
+		fmt.Printf("✅ События сохранены в %s\n", *output)
+	}
+	if *strategyName == "" {
+		return
+	}
+
+	strategy, err := trainer.GetStrategy(*strategyName)
+	if err != nil {
+		usageFatal(fs, err)
+	}
+	cfg := config.load(fs)
+	if err := applyParams(&cfg, strategy, params); err != nil {
+		usageFatal(fs, err)
+	}
+	bus, err := logging.bus(false, false)
+	if err != nil {
+		usageFatal(fs, err)
+	}
+
+	flags := trainer.Flags{Strategy: strategy.Name(), Seed: *seed, Config: &cfg, Bus: bus}
+	fmt.Printf("📈 Используется стратегия: %s - %s\n", strategy.Name(), strategy.Description())
+	records := trainer.ReverseRecords(trainer.GenerateRecordsFromEvents(imported.Events, flags, strategy))
+
+	if *csvOutput != "" {
+		meta := trainer.ParamsMeta(strategy, cfg)
+		meta["strategy"] = strategy.Name()
+		meta["bookmaker"] = *bookmaker
+		csvOptions := trainer.CSVOptions{Meta: meta, Bankroll: cfg.Bankroll.Enabled(), Match: true}
+		if err := trainer.SaveToCSVWithOptions(records, *csvOutput, csvOptions); err != nil {
+			log.Fatalf("Ошибка сохранения CSV: %v", err)
+		}
+		fmt.Printf("✅ Данные сохранены в %s\n", *csvOutput)
+	}
+
+	eventsFromOldest := make([]string, len(imported.Events))
+	for i, event := range imported.Events {
+		eventsFromOldest[i] = event.Result
+	}
+	generateStatsAndPrint(records, eventsFromOldest)
+}
+
+// splitList разбирает список через запятую
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseDate разбирает дату YYYY-MM-DD (пустая строка - нулевая дата)
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD", s)
+	}
+	return date, nil
+}
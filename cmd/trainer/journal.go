@@ -0,0 +1,148 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/holygun/go-trainer/journal"
+	"github.com/holygun/go-trainer/trainer"
+)
+
+// runJournal режим "trainer journal new_line_0000": читает журнал ставок,
+// сверяет записанные ставки с расчетом стратегии, сохраняет сессию советника
+// и лист следующих ставок в том же формате
+func runJournal(args []string) {
+	fs := flag.NewFlagSet("journal", flag.ExitOnError)
+	var (
+		sport        = fs.String("sport", "", "Раздел журнала (Football, Hockey); по умолчанию все")
+		strategyName = fs.String("strategy", "xlDrop", "Имя стратегии для сверки")
+		oddsFrom     = fs.String("odds-from", "", "Коэффициенты прошлых событий из .input или CSV файла (иначе генерируются по -seed)")
+		oddsString   = fs.String("odds", "", "Коэффициенты матча oddF,oddX,oddL (по умолчанию событие матча из -odds-from)")
+		seed         = fs.Int64("seed", 0, "Seed генератора коэффициентов прошлых событий")
+		sessionFile  = fs.String("session", "", "Сохранить сессию советника по разделу (нужен -sport)")
+		sheetFile    = fs.String("sheet", "", "Записать журнал со ставками стратегии")
+		match        = fs.String("match", "", "Матч для листа ставок (по умолчанию из журнала)")
+		params       paramFlags
+	)
+	fs.Var(&params, "param", "Параметр стратегии name=value (можно повторять)")
+	config := addConfigFlags(fs)
+	logging := addLogFlags(fs, "error")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Использование: trainer journal <файл> [флаги]\n")
+		fs.PrintDefaults()
+	}
+
+	// Файл может стоять как до флагов, так и после них
+	filename := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		filename, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if filename == "" && fs.NArg() > 0 {
+		filename = fs.Arg(0)
+	}
+	if filename == "" || (*sessionFile != "" && *sport == "") {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	book, err := journal.ParseFile(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	strategy, err := trainer.GetStrategy(*strategyName)
+	if err != nil {
+		usageFatal(fs, err)
+	}
+	cfg := config.load(fs)
+	if err := applyParams(&cfg, strategy, params); err != nil {
+		usageFatal(fs, err)
+	}
+	bus, err := logging.bus(false, false)
+	if err != nil {
+		usageFatal(fs, err)
+	}
+
+	fmt.Printf("📒 Журнал %s: итог %.0f, разделов %d\n", filename, book.Balance, len(book.Sections))
+	for _, result := range book.Results {
+		fmt.Printf("   %s: %.0f\n", result.Date.Format("01.2006"), result.Amount)
+	}
+
+	checked := 0
+	for i := range book.Sections {
+		section := &book.Sections[i]
+		if *sport != "" && !strings.EqualFold(section.Sport, *sport) {
+			continue
+		}
+		checked++
+
+		flags := trainer.Flags{Strategy: strategy.Name(), Seed: *seed, Config: &cfg, Bus: bus, Hockey: section.Hockey()}
+		var provider trainer.OddsProvider
+		if *oddsFrom != "" {
+			provider, err = newFileOddsProvider(*oddsFrom, trainer.NewRandomOddsProvider(*seed, flags), flags)
+			if err != nil {
+				log.Fatalf("Ошибка чтения коэффициентов из %s: %v", *oddsFrom, err)
+			}
+		}
+		session := journal.NewSession(*section, strategy, flags, provider)
+
+		fmt.Printf("\n---%s--- %s: событий %d, итог журнала %.0f, итог стратегии %.0f\n",
+			section.Sport, section.Match, len(section.Events), section.Total, session.Last.Total)
+
+		odds, ok := section.MatchOdds(provider)
+		if *oddsString != "" {
+			if odds, err = parseOdds(*oddsString); err != nil {
+				usageFatal(fs, err)
+			}
+			ok = true
+		}
+		if !ok {
+			fmt.Println("   Нет коэффициентов матча: задайте -odds или -odds-from с событием матча после событий раздела")
+			continue
+		}
+
+		decision, err := session.Advise(odds)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("   Коэффициенты: F=%.2f X=%.2f L=%.2f\n", odds.OddF, odds.OddX, odds.OddL)
+		fmt.Printf("   %-3s %12s %12s %12s %12s\n", "", "база", "ставка", "по расчету", "убыток")
+		for _, outcome := range trainer.Outcomes {
+			bet, _ := section.Bet(outcome)
+			fmt.Printf("   %-3s %12.0f %12.0f %12.0f %12.0f\n", outcome,
+				bet.Base, bet.Stake, decision.Bets.Get(outcome), decision.Losses.Get(outcome))
+		}
+		if mismatches := journal.Check(*section, decision); len(mismatches) == 0 {
+			fmt.Println("   ✅ Ставки журнала совпадают с расчетом стратегии")
+		} else {
+			fmt.Printf("   ⚠️ Расхождений: %d\n", len(mismatches))
+		}
+
+		if *sessionFile != "" {
+			if err := session.Save(*sessionFile); err != nil {
+				log.Fatalf("Ошибка сохранения сессии: %v", err)
+			}
+			fmt.Printf("💾 Сессия сохранена в %s\n", *sessionFile)
+		}
+		if *sheetFile != "" {
+			name := section.Match
+			if *match != "" {
+				name = *match
+			}
+			*section = journal.Sheet(*section, decision, name, cfg.DefaultBetF)
+		}
+	}
+	if checked == 0 {
+		log.Fatalf("В журнале нет раздела %s", *sport)
+	}
+
+	if *sheetFile != "" {
+		if err := journal.WriteFile(*sheetFile, book); err != nil {
+			log.Fatalf("Ошибка записи %s: %v", *sheetFile, err)
+		}
+		fmt.Printf("✅ Лист ставок сохранен в %s\n", *sheetFile)
+	}
+}
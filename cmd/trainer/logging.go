@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/holygun/go-trainer/trainer"
+)
+
+// logFlags флаги -log-format и -log-level подкоманды
+type logFlags struct {
+	format       *string
+	level        *string
+	defaultLevel string
+}
+
+// addLogFlags объявляет -log-format и -log-level. Без -log-level уровень
+// определяется флагами -debug (debug) и -verbose (info), иначе defaultLevel.
+func addLogFlags(fs *flag.FlagSet, defaultLevel string) logFlags {
+	return logFlags{
+		format: fs.String("log-format", "console", "Формат событий расчета: console (stdout), json или slog (stderr)"),
+		level: fs.String("log-level", "", fmt.Sprintf("Минимальный уровень событий: debug, info, warn или error (по умолчанию %s, с -verbose - info, с -debug - debug)",
+			defaultLevel)),
+		defaultLevel: defaultLevel,
+	}
+}
+
+// bus создает шину событий с выбранным получателем
+func (l logFlags) bus(debug, verbose bool) (*trainer.EventBus, error) {
+	name := *l.level
+	switch {
+	case name != "":
+	case debug:
+		name = "debug"
+	case verbose:
+		name = "info"
+	default:
+		name = l.defaultLevel
+	}
+	level, err := trainer.ParseLevel(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var sink trainer.Sink
+	switch *l.format {
+	case "console":
+		sink = trainer.NewConsoleSink(os.Stdout)
+	case "json":
+		sink = trainer.NewJSONSink(os.Stderr)
+	case "slog":
+		handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: trainer.SlogLevel(level)})
+		sink = trainer.NewSlogSink(slog.New(handler))
+	default:
+		return nil, fmt.Errorf("unknown log format %q (expected console, json or slog)", *l.format)
+	}
+
+	bus := trainer.NewEventBus()
+	bus.Subscribe(sink, level)
+	return bus, nil
+}
@@ -3,11 +3,12 @@ package main
 import (
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/holygun/go-trainer/trainer"
 )
@@ -15,20 +16,42 @@ import (
 const EVENTS = "X/F/L/X/F/F/X/F/F/X/X/F/F/X/X/F/F/X/F/F/X/F/F/X/X/F/F/F/X/F/L/F/X/X/F/F/X/L/L/X/F/L/F/F/F/X/L/F/F/X/X/L/X/F/F/X/F/F/L/F/F/F/L/F/L/X/F/L/F/L/X/L/F/L/F/F/F/L/L/X/X/F/F/F/L/X/L/F/F/X/L/L/F/F/X/X/F/X/L/F/F/F/X/L/X/L/F/L/F/F/L/F/F/X/F/X/X/F/F/F/F/F/X/F/X/L/L/F/F/F/F/L/L/F/L/F/X/F/F/X/L/L/L/X/X/L/L/F/X/F/F/F/F/F/F/F/F/F/L/F/F/X/L/F/F/X/L/X/X/F/X/F/X/L/F/X/F/F/F/X/F/X/F/X/X/X/F/L/L/X/F/F/F/L/F/F/L/F/L/F/X/F/X/F/F/X/F/F/X/F/F/X/F/F/L/F/F/L/F/F/F/F/F/F/F/F/F/F/L/F/L/F/F/F/F/F/F/X/F/F/F/F/F/F/L/F/F/F/F/F/X/F/F/X/X/L/L/L/F/X/X/X/F/L/F/L/X/X/F/X/F/F/F/F/X/F/L/X/L/L/L/F/F/X/F/F/F/F/X/L/L/F/X/F/F/F/F/F/X/F/F/X/F/F/F/F/F/X/L/F/F/L/F/X/X/F/X/L/X/F/F/F/L/L/F/F/F/X/F/L/L/F/L/F/L/F/L"
 const EVENTS_HOCKEY = "F/X/X/X/L/L/L/L/L/L/F/F/X/X/X/F/F/F/X/L/X/X/X/F/X/L/L/F/X/L/X/F/X/F/L/X/F/F/F/X/L/X/X/X/F/F/F/L/F/F/L/F/L/L/L/F/X/F/L/F/L/L/F/L/X/F/F/F/L/F/F/F/F/F/L/F/F/X/F/F/L/X/F/F/F/F/F/F/L/F/X/F/X/F/X/X/F/F/F/F/F/F/X/L"
 
-func main() {
-	// Парсинг аргументов командной строки
+// runSimulate режим "trainer simulate": расчет стратегии по строке событий
+// с генерируемыми или взятыми из файла коэффициентами
+func runSimulate(args []string) {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
 	var (
-		inputString  = flag.String("input", "", "Строка событий F/X/L")
-		outputFile   = flag.String("output", "trainer_output.csv", "Имя выходного CSV файла")
-		verbose      = flag.Bool("verbose", false, "Подробный вывод")
-		debug        = flag.Bool("debug", false, "Подробный вывод в тестах")
-		printReport  = flag.String("report", "", "Имя входного CSV файла")
-		hockey       = flag.Bool("hockey", false, "События хоккея")
-		strategyName = flag.String("strategy", "xlDrop", "Имя стратегии для использования")
-		realGames    = flag.Bool("real", false, "Обработка реальных игр из папки real-games")
-		force        = flag.Bool("force", false, "Игнорирование отдельных ограничений")
+		inputString  = fs.String("input", "", "Строка событий F/X/L (новые слева; по умолчанию встроенная история)")
+		outputFile   = fs.String("output", "trainer_output.csv", "Имя выходного CSV файла")
+		verbose      = fs.Bool("verbose", false, "Подробный вывод")
+		debug        = fs.Bool("debug", false, "Подробный вывод в тестах")
+		hockey       = fs.Bool("hockey", false, "События хоккея: встроенная хоккейная история и диапазоны коэффициентов")
+		strategyName = fs.String("strategy", "xlDrop", "Имя стратегии для использования")
+		seed         = fs.Int64("seed", 0, "Seed генератора коэффициентов (по умолчанию случайный)")
+		oddsFrom     = fs.String("odds-from", "", "Взять коэффициенты из .input или CSV файла")
+		withEquity   = fs.Bool("equity", false, "Добавить в CSV колонку equity (капитал с учетом неотыгранных убытков)")
+		withReaction = fs.Bool("reactions", false, "Добавить в CSV колонку reaction (примененная реакция на паттерн)")
+		wide         = fs.Bool("wide", false, "Широкий CSV: колонки trace.* с промежуточными значениями стратегии (для trainer explain)")
+		params       paramFlags
 	)
-	flag.Parse()
+	fs.Var(&params, "param", "Параметр стратегии name=value (можно повторять)")
+	config := addConfigFlags(fs)
+	logging := addLogFlags(fs, "warn")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		usageFatal(fs, fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+
+	// Без явного -seed берем случайный, но записываем его в результат
+	seedSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			seedSet = true
+		}
+	})
+	if !seedSet {
+		*seed = time.Now().UnixNano()
+	}
 
 	// Создание структуры флагов
 	flags := trainer.Flags{
@@ -36,29 +59,27 @@ func main() {
 		Output:   *outputFile,
 		Verbose:  *verbose,
 		Debug:    *debug,
-		Report:   *printReport,
 		Hockey:   *hockey,
 		Strategy: *strategyName,
-		Real:     *realGames,
-		Force:    *force,
-		Testing:  false,
+		Seed:     *seed,
+		Trace:    *wide,
 	}
-
-	if flags.Report != "" {
-		readCSVAndPrint(flags.Report)
-		return
+	bus, err := logging.bus(*debug, *verbose)
+	if err != nil {
+		usageFatal(fs, err)
 	}
+	flags.Bus = bus
 
-	realGamesFlags := map[string]bool{
-		"hockey": false,
+	// Получение стратегии
+	strategy, err := trainer.GetStrategy(flags.Strategy)
+	if err != nil {
+		usageFatal(fs, err)
 	}
-	if flags.Real {
-		if flags.Hockey {
-			realGamesFlags["hockey"] = true
-		}
-		processRealGames(flags, realGamesFlags)
-		return
+	cfg := config.load(fs)
+	if err := applyParams(&cfg, strategy, params); err != nil {
+		usageFatal(fs, err)
 	}
+	flags.Config = &cfg
 
 	if flags.Input == "" && flags.Hockey {
 		flags.Input = EVENTS_HOCKEY
@@ -69,7 +90,7 @@ func main() {
 	// Парсинг событий
 	events := trainer.ParseEvents(flags.Input)
 	if len(events) == 0 {
-		log.Fatal("Не найдено корректных событий F/X/L во входной строке")
+		usageFatal(fs, fmt.Errorf("no valid F/X/L events in -input %q", flags.Input))
 	}
 
 	fmt.Printf("📊 Обработка %d событий: %v\n", len(events), strings.Join(events, "/"))
@@ -77,22 +98,36 @@ func main() {
 	// Реверсируем для обработки от старых к новым
 	eventsFromOldest := trainer.ReverseSlice(events)
 
-	// Получение стратегии
-	strategy, err := trainer.GetStrategy(flags.Strategy)
-	if err != nil {
-		log.Fatal(err)
+	fmt.Printf("📈 Используется стратегия: %s - %s\n", strategy.Name(), strategy.Description())
+
+	// Источник коэффициентов
+	meta := trainer.ParamsMeta(strategy, cfg)
+	meta["seed"] = strconv.FormatInt(flags.Seed, 10)
+	meta["strategy"] = strategy.Name()
+	var provider trainer.OddsProvider = trainer.NewRandomOddsProvider(flags.Seed, flags)
+	if *oddsFrom != "" {
+		provider, err = newFileOddsProvider(*oddsFrom, provider, flags)
+		if err != nil {
+			log.Fatalf("Ошибка чтения коэффициентов из %s: %v", *oddsFrom, err)
+		}
+		meta["odds_from"] = filepath.Base(*oddsFrom)
+	}
+	if *config.file != "" {
+		meta["config"] = filepath.Base(*config.file)
 	}
 
-	fmt.Printf("📈 Используется стратегия: %s - %s\n", strategy.Name(), strategy.Description())
+	fmt.Printf("🎲 Seed: %d\n", flags.Seed)
 
 	// Генерация записей
-	records := trainer.GenerateRecords(eventsFromOldest, flags, strategy)
+	records := trainer.Simulate(eventsFromOldest, provider, flags, strategy)
 
 	// Реверсируем обратно для отображения новых сверху
 	records = trainer.ReverseRecords(records)
 
 	// Сохранение в CSV
-	if err := trainer.SaveToCSV(records, flags.Output); err != nil {
+	csvOptions := trainer.CSVOptions{Meta: meta, Bankroll: cfg.Bankroll.Enabled(), Equity: *withEquity, Reaction: *withReaction, Trace: *wide,
+		Match: trainer.HasMatchData(records)}
+	if err := trainer.SaveToCSVWithOptions(records, flags.Output, csvOptions); err != nil {
 		log.Fatalf("Ошибка сохранения CSV: %v", err)
 	}
 
@@ -102,195 +137,127 @@ func main() {
 	generateStatsAndPrint(records, eventsFromOldest)
 }
 
-func readCSVAndPrint(filename string) {
-	records, err := trainer.ReadCSV(filename)
-	if err != nil {
-		log.Fatal(err)
-		return
+// runReport режим "trainer report run.csv": отчет по сохраненному CSV
+func runReport(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Использование: trainer report <файл.csv>\n")
+		fs.PrintDefaults()
 	}
-	eventsFromNewest := []string{}
-	for i := 0; i < len(records); i++ {
-		eventsFromNewest = append(eventsFromNewest, records[i].Result)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(exitUsage)
 	}
-	// Генерация и вывод статистики
-	generateStatsAndPrint(records, trainer.ReverseSlice(eventsFromNewest))
+	readCSVAndPrint(fs.Arg(0))
 }
 
-func generateStatsAndPrint(records []trainer.TrainerRecord, eventsFromOldest []string) {
-	stats := trainer.CalculateStats(records, eventsFromOldest)
-	trainer.PrintReport(stats, records)
+// configFlags флаги файла конфигурации и лимитов банка подкоманды
+type configFlags struct {
+	file     *string
+	capital  *float64
+	maxStake *float64
+	maxBet   *string
+	onCap    *string
 }
 
-// processRealGames обрабатывает реальные игры из папки real-games
-func processRealGames(flags trainer.Flags, realGamesFlags map[string]bool) {
-	realGamesDir := "real-games"
-
-	// Проверяем существование папки
-	if _, err := os.Stat(realGamesDir); os.IsNotExist(err) {
-		fmt.Printf("Папка %s не существует. Создаем...\n", realGamesDir)
-		if err := os.MkdirAll(realGamesDir, 0755); err != nil {
-			log.Fatalf("Ошибка создания папки %s: %v", realGamesDir, err)
-		}
-		fmt.Printf("Папка %s создана. Добавьте .input файлы для обработки.\n", realGamesDir)
-		return
-	}
-
-	// Читаем все файлы в папке
-	files, err := ioutil.ReadDir(realGamesDir)
-	if err != nil {
-		log.Fatalf("Ошибка чтения папки %s: %v", realGamesDir, err)
+// addConfigFlags объявляет -config и флаги банка
+func addConfigFlags(fs *flag.FlagSet) configFlags {
+	return configFlags{
+		file:     fs.String("config", "", "Файл конфигурации (JSON или YAML)"),
+		capital:  fs.Float64("capital", 0, "Начальный банк (0 - без ограничения)"),
+		maxStake: fs.Float64("max-stake", 0, "Лимит суммы ставок на событие (0 - без ограничения)"),
+		maxBet:   fs.String("max-bet", "", "Лимит ставки на исход: число или F=..,X=..,L=.."),
+		onCap:    fs.String("on-cap", "", "Реакция на лимит: stop, clamp или writeoff"),
 	}
+}
 
-	// Фильтруем .input файлы
-	var inputFiles []os.FileInfo
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".input") {
-			inputFiles = append(inputFiles, file)
+// load читает конфигурацию (ошибка файла - ошибка данных) и переопределяет
+// банк значениями флагов (ошибка значения - ошибка использования)
+func (c configFlags) load(fs *flag.FlagSet) trainer.Config {
+	cfg := trainer.DefaultConfig()
+	if *c.file != "" {
+		loaded, err := trainer.LoadConfig(*c.file)
+		if err != nil {
+			log.Fatal(err)
 		}
+		cfg = loaded
 	}
-
-	if len(inputFiles) == 0 {
-		fmt.Printf("В папке %s не найдено .input файлов\n", realGamesDir)
-		return
-	}
-
-	fmt.Printf("Найдено %d .input файлов в папке %s\n", len(inputFiles), realGamesDir)
-
-	// Обрабатываем каждый файл
-	for _, file := range inputFiles {
-		processInputFile(filepath.Join(realGamesDir, file.Name()), flags, realGamesFlags)
+	if err := applyBankrollFlags(&cfg, *c.capital, *c.maxStake, *c.maxBet, *c.onCap); err != nil {
+		usageFatal(fs, err)
 	}
+	return cfg
 }
 
-// processInputFile обрабатывает один input файл
-func processInputFile(filePath string, flags trainer.Flags, realGamesFlags map[string]bool) {
-	fileName := filepath.Base(filePath)
-
-	// Проверяем наличие флага в имени файла
-	baseName := strings.TrimSuffix(fileName, ".input")
-	parts := strings.Split(baseName, "-")
-
-	strategy, err := trainer.GetStrategy(parts[0])
-	if err != nil {
-		fmt.Printf("Ошибка получения стратегии: %v\n", err)
-		return
+// applyBankrollFlags переопределяет банк и лимиты ставок значениями флагов
+func applyBankrollFlags(cfg *trainer.Config, capital, maxStake float64, maxBet, onCap string) error {
+	if capital != 0 {
+		cfg.Bankroll.Capital = capital
 	}
-
-	var fileFlag string
-	if len(parts) > 1 {
-		fileFlag = parts[len(parts)-1]
-	}
-
-	if flags.Debug {
-		fmt.Printf("DEBUG: Файл %s, флаг: %s\n", fileName, fileFlag)
+	if maxStake != 0 {
+		cfg.Bankroll.MaxStake = maxStake
 	}
-
-	// Проверяем, нужно ли обрабатывать этот файл
-	if fileFlag != "" {
-		// Если у файла есть флаг, проверяем, зарегистрирован ли он
-		_, ok := realGamesFlags[fileFlag]
-		if !ok {
-			fmt.Printf("Ошибка: флаг '%s' в файле %s не зарегистрирован\n", fileFlag, fileName)
-			fmt.Printf("Зарегистрированные флаги: %v\n", realGamesFlags)
-			os.Exit(1)
-		}
-
-		// Если есть активные флаги, обрабатываем только файлы с этими флагами
-		if len(realGamesFlags) > 0 {
-			if !realGamesFlags[fileFlag] {
-				if flags.Debug {
-					fmt.Printf("DEBUG: Пропускаем файл %s (флаг %s не активен)\n", fileName, fileFlag)
-				}
-				return
-			}
-		} else {
-			// Если активных флагов нет, пропускаем файлы с флагами
-			if flags.Debug {
-				fmt.Printf("DEBUG: Пропускаем файл %s (есть флаг %s, но активные флаги не указаны)\n", fileName, fileFlag)
-			}
-			return
-		}
-	} else {
-		// Если у файла нет флага, но есть активные флаги, пропускаем его
-		if hasTrue(realGamesFlags) {
-			if flags.Debug {
-				fmt.Printf("DEBUG: Пропускаем файл %s (нет флага, но есть активные флаги)\n", fileName)
-			}
-			return
+	if maxBet != "" {
+		limits, err := parseMaxBet(maxBet)
+		if err != nil {
+			return err
 		}
+		cfg.Bankroll.MaxBet = limits
 	}
-
-	actualFilePath := strings.TrimSuffix(filePath, ".input") + ".actual"
-
-	// Проверяем существование actual файла и сравниваем количество строк
-	if _, err := os.Stat(actualFilePath); err == nil {
-		inputLines, err1 := countLines(filePath)
-		actualLines, err2 := countLines(actualFilePath)
-
-		if err1 == nil && err2 == nil && inputLines == actualLines && !flags.Force {
-			fmt.Printf("Файл %s не требует обновления (количество строк совпадает)\n", fileName)
-			return
-		}
+	if onCap != "" {
+		cfg.Bankroll.OnCap = onCap
 	}
+	return cfg.Validate()
+}
 
-	fmt.Printf("Обрабатываем файл: %s\n", fileName)
-
-	// Читаем input файл
-	events, err := trainer.ReadInputFile(filePath)
-	if err != nil {
-		fmt.Printf("Ошибка чтения файла %s: %v\n", fileName, err)
-		return
+// parseMaxBet парсит лимит ставки на исход: одно число для всех исходов
+// или значения по исходам вида "F=100000,X=50000,L=50000"
+func parseMaxBet(s string) (trainer.PerOutcome, error) {
+	if value, err := strconv.ParseFloat(s, 64); err == nil {
+		return trainer.PerOutcome{F: value, X: value, L: value}, nil
 	}
 
-	// Извлекаем события и коэффициенты
-	eventStrings := make([]string, len(events))
-	odds := make([]struct{ OddF, OddX, OddL float64 }, len(events))
-
-	for i, event := range events {
-		eventStrings[i] = event.Result
-		odds[i] = struct{ OddF, OddX, OddL float64 }{
-			OddF: event.OddF,
-			OddX: event.OddX,
-			OddL: event.OddL,
+	var limits trainer.PerOutcome
+	for _, part := range strings.Split(s, ",") {
+		outcome, rawValue, ok := strings.Cut(strings.TrimSpace(part), "=")
+		outcome = strings.ToUpper(outcome)
+		value, err := strconv.ParseFloat(rawValue, 64)
+		if !ok || err != nil || (outcome != "F" && outcome != "X" && outcome != "L") {
+			return limits, fmt.Errorf("invalid -max-bet %q: expected a number or F=..,X=..,L=..", s)
 		}
+		limits.Set(outcome, value)
 	}
+	return limits, nil
+}
 
-	// Генерируем записи с использованием стратегии
-	generatedRecords := trainer.GenerateRecordsWithOdds(eventStrings, odds, flags, strategy)
-
-	// Сохраняем в actual файл
-	if err := trainer.SaveToCSV(generatedRecords, actualFilePath); err != nil {
-		fmt.Printf("Ошибка сохранения файла %s: %v\n", actualFilePath, err)
-		return
+// newFileOddsProvider создает провайдер коэффициентов из .input файла или
+// из ранее сохраненного CSV
+func newFileOddsProvider(filename string, fallback trainer.OddsProvider, flags trainer.Flags) (trainer.OddsProvider, error) {
+	if strings.HasSuffix(filename, ".input") {
+		events, err := trainer.ReadInputFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		return trainer.NewInputOddsProvider(events, fallback, flags), nil
 	}
-
-	fmt.Printf("Файл %s успешно обработан и сохранен как %s\n", fileName, filepath.Base(actualFilePath))
+	return trainer.NewReplayOddsProvider(filename, fallback, flags)
 }
 
-// countLines подсчитывает количество строк в файле
-func countLines(filePath string) (int, error) {
-	content, err := ioutil.ReadFile(filePath)
+func readCSVAndPrint(filename string) {
+	records, err := trainer.ReadCSV(filename)
 	if err != nil {
-		return 0, err
+		log.Fatal(err)
+		return
 	}
-
-	lines := strings.Split(string(content), "\n")
-	// Убираем пустые строки в конце
-	count := 0
-	for _, line := range lines {
-		if strings.TrimSpace(line) != "" {
-			count++
-		}
+	eventsFromNewest := []string{}
+	for i := 0; i < len(records); i++ {
+		eventsFromNewest = append(eventsFromNewest, records[i].Result)
 	}
-
-	return count, nil
+	// Генерация и вывод статистики
+	generateStatsAndPrint(records, trainer.ReverseSlice(eventsFromNewest))
 }
 
-func hasTrue(m map[string]bool) bool {
-	for _, value := range m {
-		if value {
-			return true
-		}
-	}
-	return false
+func generateStatsAndPrint(records []trainer.TrainerRecord, eventsFromOldest []string) {
+	stats := trainer.CalculateStats(records, eventsFromOldest)
+	trainer.PrintReport(stats, records)
 }
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// TestMain lets tests run the trainer binary: the test binary started with
+// TRAINER_MAIN=1 runs main with its arguments instead of the tests
+func TestMain(m *testing.M) {
+	if os.Getenv("TRAINER_MAIN") == "1" {
+		main()
+		os.Exit(exitOK)
+	}
+	os.Exit(m.Run())
+}
+
+// runTrainer runs "trainer args..." in dir and returns the combined output
+// and exit code
+func runTrainer(t *testing.T, dir string, args ...string) (string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "TRAINER_MAIN=1")
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return output.String(), exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatalf("run trainer %v: %v", args, err)
+	}
+	return output.String(), exitOK
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/holygun/go-trainer/trainer"
+)
+
+// runMonteCarlo режим "trainer montecarlo": распределения результатов стратегии
+// на случайных последовательностях событий
+func runMonteCarlo(args []string) {
+	fs := flag.NewFlagSet("montecarlo", flag.ExitOnError)
+	var (
+		strategyName = fs.String("strategy", "xlDrop", "Имя стратегии")
+		runs         = fs.Int("n", 1000, "Количество последовательностей")
+		events       = fs.Int("events", 300, "Количество событий в последовательности")
+		workers      = fs.Int("workers", 0, "Количество параллельных обработчиков (0 - по числу CPU)")
+		seed         = fs.Int64("seed", 1, "Базовый seed")
+		exposure     = fs.Float64("exposure", 500000, "Порог суммы ставок на событие")
+		hockey       = fs.Bool("hockey", false, "События хоккея")
+		configFile   = fs.String("config", "", "Файл конфигурации (JSON или YAML)")
+		outputFile   = fs.String("output", "", "CSV с результатами каждой последовательности")
+		params       paramFlags
+	)
+	fs.Var(&params, "param", "Параметр стратегии name=value (можно повторять)")
+	fs.Parse(args)
+
+	strategy, err := trainer.GetStrategy(*strategyName)
+	if err != nil {
+		usageFatal(fs, err)
+	}
+
+	cfg := trainer.DefaultConfig()
+	if *configFile != "" {
+		cfg, err = trainer.LoadConfig(*configFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := applyParams(&cfg, strategy, params); err != nil {
+		usageFatal(fs, err)
+	}
+
+	if *runs <= 0 || *events <= 0 {
+		usageFatal(fs, errors.New("-n и -events должны быть положительными"))
+	}
+
+	flags := trainer.Flags{Strategy: strategy.Name(), Hockey: *hockey, Config: &cfg}
+	opts := trainer.MonteCarloOptions{
+		Runs:     *runs,
+		Events:   *events,
+		Workers:  *workers,
+		Seed:     *seed,
+		Exposure: *exposure,
+	}
+
+	fmt.Printf("🎲 %d последовательностей по %d событий, стратегия %s\n", opts.Runs, opts.Events, strategy.Name())
+	report := trainer.RunMonteCarlo(strategy, flags, opts)
+	trainer.PrintMonteCarloReport(report)
+
+	if *outputFile != "" {
+		if err := saveMonteCarloRuns(report, *outputFile); err != nil {
+			log.Fatalf("Ошибка сохранения CSV: %v", err)
+		}
+		fmt.Printf("\n✅ Результаты последовательностей сохранены в %s\n", *outputFile)
+	}
+}
+
+// saveMonteCarloRuns сохраняет результаты каждой последовательности в CSV
+func saveMonteCarloRuns(report trainer.MonteCarloReport, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	headers := []string{"seed", "total", "peak_bet", "peak_stake", "peak_lossF", "peak_lossX", "peak_lossL", "red", "yellow", "green", "stopped_at", "ruined"}
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+
+	format := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', 0, 64)
+	}
+	for _, run := range report.Runs {
+		row := []string{
+			strconv.FormatInt(run.Seed, 10),
+			format(run.FinalTotal),
+			format(run.PeakBet),
+			format(run.PeakStake),
+			format(run.PeakLosses.F),
+			format(run.PeakLosses.X),
+			format(run.PeakLosses.L),
+			strconv.Itoa(run.Patterns["RED"]),
+			strconv.Itoa(run.Patterns["YELLOW"]),
+			strconv.Itoa(run.Patterns["GREEN"]),
+			strconv.Itoa(run.StoppedAt),
+			strconv.FormatBool(run.Ruined),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return writer.Error()
+}
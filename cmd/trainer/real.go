@@ -0,0 +1,232 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/holygun/go-trainer/trainer"
+)
+
+// runReal режим "trainer real": расчет .input файлов из папки real-games
+// в .actual файлы. Стратегия и теги берутся из имени файла.
+func runReal(args []string) {
+	fs := flag.NewFlagSet("real", flag.ExitOnError)
+	var (
+		tags        = fs.String("tags", "", "Обрабатывать только файлы хотя бы с одним из тегов (через запятую)")
+		excludeTags = fs.String("exclude-tags", "", "Пропускать файлы с любым из тегов (через запятую)")
+		hockey      = fs.Bool("hockey", false, "То же, что -tags hockey")
+		force       = fs.Bool("force", false, "Пересчитать файлы, даже если .actual не требует обновления")
+		dryRun      = fs.Bool("dry-run", false, "Только показать, какие файлы будут пересчитаны и почему")
+		verbose     = fs.Bool("verbose", false, "Подробный вывод")
+		debug       = fs.Bool("debug", false, "Подробный вывод: какие файлы пропущены и почему")
+		wide        = fs.Bool("wide", false, "Колонки trace.* с промежуточными значениями стратегии в .actual файлах")
+		params      paramFlags
+	)
+	fs.Var(&params, "param", "Параметр стратегии файла name=value (можно повторять)")
+	config := addConfigFlags(fs)
+	logging := addLogFlags(fs, "warn")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		usageFatal(fs, fmt.Errorf("unexpected argument %q", fs.Arg(0)))
+	}
+
+	bus, err := logging.bus(*debug, *verbose)
+	if err != nil {
+		usageFatal(fs, err)
+	}
+	cfg := config.load(fs)
+	flags := trainer.Flags{
+		Verbose: *verbose,
+		Debug:   *debug,
+		Real:    true,
+		Force:   *force,
+		Config:  &cfg,
+		Bus:     bus,
+		Trace:   *wide,
+	}
+
+	registry, err := trainer.NewTagRegistry(cfg.Tags...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	include := splitList(*tags)
+	if *hockey {
+		include = append(include, trainer.TagHockey)
+	}
+	var filter trainer.TagFilter
+	if filter.Include, err = registry.Resolve(include); err != nil {
+		usageFatal(fs, fmt.Errorf("-tags: %v", err))
+	}
+	if filter.Exclude, err = registry.Resolve(splitList(*excludeTags)); err != nil {
+		usageFatal(fs, fmt.Errorf("-exclude-tags: %v", err))
+	}
+
+	run := realGamesRun{flags: flags, registry: registry, filter: filter, params: params, dryRun: *dryRun}
+	if failed := processRealGames(run); failed > 0 {
+		fmt.Printf("Файлов с ошибками: %d\n", failed)
+		os.Exit(exitData)
+	}
+}
+
+// realGamesRun настройки обработки папки real-games
+type realGamesRun struct {
+	flags    trainer.Flags
+	registry *trainer.TagRegistry
+	filter   trainer.TagFilter
+	params   paramFlags // -param для стратегии каждого файла
+	dryRun   bool       // Только перечислить файлы, которые будут пересчитаны
+}
+
+// processRealGames обрабатывает реальные игры из папки real-games и
+// возвращает количество файлов с ошибками
+func processRealGames(run realGamesRun) int {
+	realGamesDir := "real-games"
+
+	// Проверяем существование папки
+	if _, err := os.Stat(realGamesDir); os.IsNotExist(err) {
+		fmt.Printf("Папка %s не существует. Создаем...\n", realGamesDir)
+		if err := os.MkdirAll(realGamesDir, 0755); err != nil {
+			log.Fatalf("Ошибка создания папки %s: %v", realGamesDir, err)
+		}
+		fmt.Printf("Папка %s создана. Добавьте .input файлы для обработки.\n", realGamesDir)
+		return 0
+	}
+
+	// Читаем все файлы в папке
+	files, err := ioutil.ReadDir(realGamesDir)
+	if err != nil {
+		log.Fatalf("Ошибка чтения папки %s: %v", realGamesDir, err)
+	}
+
+	// Фильтруем .input файлы
+	var inputFiles []os.FileInfo
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".input") {
+			inputFiles = append(inputFiles, file)
+		}
+	}
+
+	if len(inputFiles) == 0 {
+		fmt.Printf("В папке %s не найдено .input файлов\n", realGamesDir)
+		return 0
+	}
+
+	fmt.Printf("Найдено %d .input файлов в папке %s\n", len(inputFiles), realGamesDir)
+
+	// Обрабатываем каждый файл; ошибка одного файла не останавливает остальные
+	failed := 0
+	for _, file := range inputFiles {
+		if err := processInputFile(filepath.Join(realGamesDir, file.Name()), run); err != nil {
+			fmt.Printf("Ошибка: %v\n", err)
+			failed++
+		}
+	}
+	return failed
+}
+
+// processInputFile обрабатывает один input файл
+func processInputFile(filePath string, run realGamesRun) error {
+	fileName := filepath.Base(filePath)
+	flags := run.flags
+
+	// Стратегия и теги из имени файла, настройки из .meta файла
+	strategyName, fileTags := trainer.ParseRealGameName(fileName)
+	gameMeta, err := trainer.LoadRealGameMeta(filePath)
+	if err != nil {
+		return err
+	}
+	tags, err := run.registry.Resolve(append(fileTags, gameMeta.Tags...))
+	if err != nil {
+		return fmt.Errorf("%s: %v", fileName, err)
+	}
+
+	if flags.Debug {
+		fmt.Printf("DEBUG: Файл %s, теги: %v\n", fileName, tags)
+	}
+
+	// Проверяем, нужно ли обрабатывать этот файл
+	if ok, reason := run.filter.Match(tags); !ok {
+		if flags.Debug {
+			fmt.Printf("DEBUG: Пропускаем файл %s (%s)\n", fileName, reason)
+		}
+		return nil
+	}
+
+	settings, err := gameMeta.Resolve(strategyName, trainer.HasTag(tags, trainer.TagHockey), flags.EffectiveConfig())
+	if err != nil {
+		return fmt.Errorf("%s: %v", fileName, err)
+	}
+	strategy, cfg := settings.Strategy, settings.Config
+	flags.Hockey = settings.Hockey
+	flags.Strategy = strategy.Name()
+
+	// Параметры -param относятся к стратегии файла и сильнее .meta
+	if err := applyParams(&cfg, strategy, run.params); err != nil {
+		return fmt.Errorf("параметры для файла %s: %v", fileName, err)
+	}
+	flags.Config = &cfg
+
+	actualFilePath := strings.TrimSuffix(filePath, ".input") + ".actual"
+
+	// Пересчитываем файл, только если изменился отпечаток расчета: .input,
+	// стратегия, ее версия или настройки
+	fingerprint, err := trainer.NewRealGameFingerprint(filePath, strategy, flags)
+	if err != nil {
+		return fmt.Errorf("чтение файла %s: %v", fileName, err)
+	}
+	reasons := []string{"флаг -force"}
+	if !flags.Force {
+		reasons = regenerateReasons(actualFilePath, fingerprint)
+	}
+	if len(reasons) == 0 {
+		fmt.Printf("Файл %s не требует обновления\n", fileName)
+		return nil
+	}
+	if run.dryRun {
+		fmt.Printf("Будет пересчитан: %s (%s)\n", fileName, strings.Join(reasons, ", "))
+		return nil
+	}
+
+	fmt.Printf("Обрабатываем файл: %s (%s)\n", fileName, strings.Join(reasons, ", "))
+
+	// Читаем input файл
+	events, err := trainer.ReadInputFile(filePath)
+	if err != nil {
+		return fmt.Errorf("чтение файла %s: %v", fileName, err)
+	}
+
+	// Генерируем записи с использованием стратегии (с метаданными матчей)
+	generatedRecords := trainer.GenerateRecordsFromEvents(events, flags, strategy)
+
+	// Сохраняем в actual файл вместе с параметрами расчета
+	meta := trainer.ParamsMeta(strategy, cfg)
+	for key, value := range fingerprint.Meta() {
+		meta[key] = value
+	}
+	csvOptions := trainer.CSVOptions{Meta: meta, Bankroll: cfg.Bankroll.Enabled(), Trace: flags.Trace,
+		Match: trainer.HasMatchData(generatedRecords)}
+	if err := trainer.SaveToCSVWithOptions(generatedRecords, actualFilePath, csvOptions); err != nil {
+		return fmt.Errorf("сохранение файла %s: %v", actualFilePath, err)
+	}
+
+	fmt.Printf("Файл %s успешно обработан и сохранен как %s\n", fileName, filepath.Base(actualFilePath))
+	return nil
+}
+
+// regenerateReasons сравнивает отпечаток расчета с сохраненным в .actual
+// файле и возвращает причины пересчета (пустой список - файл актуален)
+func regenerateReasons(actualFilePath string, fingerprint trainer.RealGameFingerprint) []string {
+	if _, err := os.Stat(actualFilePath); os.IsNotExist(err) {
+		return []string{"нет .actual"}
+	}
+	stored, err := trainer.ReadRealGameFingerprint(actualFilePath)
+	if err != nil {
+		return []string{fmt.Sprintf(".actual не читается: %v", err)}
+	}
+	return fingerprint.Changes(stored)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/holygun/go-trainer/trainer"
+)
+
+// paramFlags значения повторяемого флага -param name=value
+type paramFlags []string
+
+func (p *paramFlags) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *paramFlags) Set(value string) error {
+	if _, _, err := trainer.ParseParam(value); err != nil {
+		return err
+	}
+	*p = append(*p, value)
+	return nil
+}
+
+// applyParams переопределяет параметры стратегии значениями -param и проверяет результат
+func applyParams(cfg *trainer.Config, strategy trainer.Strategy, params paramFlags) error {
+	for _, param := range params {
+		name, value, err := trainer.ParseParam(param)
+		if err != nil {
+			return err
+		}
+		cfg.SetParam(strategy.Name(), name, value)
+	}
+	return cfg.Validate()
+}
+
+// runStrategies режим "trainer strategies": список стратегий и схема параметров
+func runStrategies(args []string) {
+	if len(args) == 0 || args[0] == "list" {
+		for _, name := range trainer.StrategyNames() {
+			strategy, _ := trainer.GetStrategy(name)
+			fmt.Printf("%-16s %s\n", strategy.Name(), strategy.Description())
+		}
+		return
+	}
+
+	if args[0] != "describe" || len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "Использование: trainer strategies [list | describe <name>]")
+		os.Exit(exitUsage)
+	}
+
+	strategy, err := trainer.GetStrategy(args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitUsage)
+	}
+
+	fmt.Printf("%s - %s\n", strategy.Name(), strategy.Description())
+	params := trainer.StrategyParams(strategy)
+	if len(params) == 0 {
+		fmt.Println("\nПараметров нет")
+		return
+	}
+
+	fmt.Printf("\n%-12s %-6s %-8s %-14s %s\n", "ПАРАМЕТР", "ТИП", "ПО УМОЛЧ.", "ДИАПАЗОН", "ОПИСАНИЕ")
+	for _, param := range params {
+		fmt.Printf("%-12s %-6s %-8s %-14s %s\n",
+			param.Name, param.Type, formatParam(param.Default),
+			"["+formatParam(param.Min)+", "+formatParam(param.Max)+"]", param.Description)
+	}
+	fmt.Printf("\nЗадаются флагом -param name=value или в конфигурации:\nstrategies:\n  %s:\n    %s: %s\n",
+		strategy.Name(), params[0].Name, formatParam(params[0].Default))
+}
+
+func formatParam(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/holygun/go-trainer/trainer"
+)
+
+// rangeFlags значения повторяемого флага -range
+type rangeFlags []trainer.SweepDimension
+
+func (r *rangeFlags) String() string {
+	names := make([]string, len(*r))
+	for i, dim := range *r {
+		names[i] = dim.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func (r *rangeFlags) Set(value string) error {
+	dim, err := trainer.ParseSweepDimension(value)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, dim)
+	return nil
+}
+
+// constraintFlags значения повторяемого флага -constraint
+type constraintFlags []trainer.SweepConstraint
+
+func (c *constraintFlags) String() string {
+	parts := make([]string, len(*c))
+	for i, constraint := range *c {
+		parts[i] = constraint.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+func (c *constraintFlags) Set(value string) error {
+	constraint, err := trainer.ParseSweepConstraint(value)
+	if err != nil {
+		return err
+	}
+	*c = append(*c, constraint)
+	return nil
+}
+
+// runSweep режим "trainer sweep": перебор параметров стратегии
+// с ранжированием по целевой функции
+func runSweep(args []string) {
+	fs := flag.NewFlagSet("sweep", flag.ExitOnError)
+	var (
+		strategyName = fs.String("strategy", "xlDrop", "Имя стратегии")
+		mode         = fs.String("mode", trainer.SweepGrid, "Режим перебора: grid, random или coord")
+		objective    = fs.String("objective", "max:total", "Целевая функция min:metric или max:metric")
+		corpus       = fs.String("corpus", "random", "Последовательности: random, real-games или results")
+		dir          = fs.String("dir", "", "Каталог для -corpus real-games/results (по умолчанию одноименный)")
+		runs         = fs.Int("n", 50, "Количество случайных последовательностей")
+		events       = fs.Int("events", 300, "Количество событий в случайной последовательности")
+		seed         = fs.Int64("seed", 1, "Базовый seed случайных последовательностей и комбинаций")
+		samples      = fs.Int("samples", 100, "Количество комбинаций в режиме random")
+		rounds       = fs.Int("rounds", 5, "Максимум проходов в режиме coord")
+		workers      = fs.Int("workers", 0, "Количество параллельных обработчиков (0 - по числу CPU)")
+		top          = fs.Int("top", 20, "Сколько лучших комбинаций вывести (0 - все)")
+		hockey       = fs.Bool("hockey", false, "События хоккея (для случайных последовательностей)")
+		configFile   = fs.String("config", "", "Файл конфигурации (JSON или YAML)")
+		outputFile   = fs.String("output", "", "CSV со всеми комбинациями в порядке ранга")
+		ranges       rangeFlags
+		constraints  constraintFlags
+		params       paramFlags
+	)
+	fs.Var(&ranges, "range", "Перебираемый параметр name=min:max:step или name=v1,v2 (можно повторять)")
+	fs.Var(&constraints, "constraint", "Ограничение metric>=value или metric<=value (можно повторять)")
+	fs.Var(&params, "param", "Фиксированный параметр стратегии name=value (можно повторять)")
+	fs.Parse(args)
+
+	strategy, err := trainer.GetStrategy(*strategyName)
+	if err != nil {
+		usageFatal(fs, err)
+	}
+
+	cfg := trainer.DefaultConfig()
+	if *configFile != "" {
+		cfg, err = trainer.LoadConfig(*configFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := applyParams(&cfg, strategy, params); err != nil {
+		usageFatal(fs, err)
+	}
+
+	goal, err := trainer.ParseSweepObjective(*objective)
+	if err != nil {
+		usageFatal(fs, err)
+	}
+	goal.Constraints = constraints
+
+	flags := trainer.Flags{Strategy: strategy.Name(), Hockey: *hockey, Seed: *seed, Config: &cfg}
+
+	var sequences []trainer.SweepSequence
+	switch *corpus {
+	case "random":
+		if *runs <= 0 || *events <= 0 {
+			usageFatal(fs, errors.New("-n и -events должны быть положительными"))
+		}
+		sequences = trainer.RandomSweepSequences(flags, *runs, *events, *seed)
+	case "real-games":
+		sequences, err = trainer.InputSweepSequences(defaultString(*dir, "real-games"))
+	case "results":
+		sequences, err = trainer.CSVSweepSequences(defaultString(*dir, "results"))
+	default:
+		log.Fatalf("Неизвестный корпус %q: ожидается random, real-games или results", *corpus)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := trainer.SweepOptions{
+		Dimensions: ranges,
+		Sequences:  sequences,
+		Objective:  goal,
+		Mode:       *mode,
+		Samples:    *samples,
+		Rounds:     *rounds,
+		Workers:    *workers,
+		Seed:       *seed,
+	}
+
+	fmt.Printf("🔍 Перебор параметров %s на %d последовательностях (%s)\n", strategy.Name(), len(sequences), *corpus)
+	report, err := trainer.RunSweep(strategy, flags, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	trainer.PrintSweepReport(report, *top)
+
+	if *outputFile != "" {
+		meta := map[string]string{
+			"strategy":  strategy.Name(),
+			"mode":      *mode,
+			"corpus":    *corpus,
+			"objective": *objective,
+			"seed":      fmt.Sprint(*seed),
+		}
+		if err := trainer.SaveSweepCSV(report, *outputFile, meta); err != nil {
+			log.Fatalf("Ошибка сохранения CSV: %v", err)
+		}
+		fmt.Printf("\n✅ Результаты перебора сохранены в %s\n", *outputFile)
+	}
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/holygun/go-trainer/trainer"
+)
+
+// runValidate режим "trainer validate": проверяет, что файлы читаются, не
+// выполняя расчет. Вид файла определяется расширением: .input - события,
+// .meta - настройки real-games файла, .json/.yaml/.yml - конфигурация, остальные (.csv, .actual, .expected) - CSV.
+func runValidate(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Использование: trainer validate <файл>...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	failed := 0
+	for _, filename := range fs.Args() {
+		summary, err := validateFile(filename)
+		if err != nil {
+			failed++
+			fmt.Printf("❌ %v\n", err)
+			continue
+		}
+		fmt.Printf("✅ %s: %s\n", filename, summary)
+	}
+	if failed > 0 {
+		fmt.Printf("Файлов с ошибками: %d из %d\n", failed, fs.NArg())
+		os.Exit(exitData)
+	}
+}
+
+// validateFile читает файл и возвращает краткое описание содержимого
+func validateFile(filename string) (string, error) {
+	switch filepath.Ext(filename) {
+	case ".input":
+		events, err := trainer.ReadInputFile(filename)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", filename, err)
+		}
+		return fmt.Sprintf("событий %d", len(events)), nil
+	case ".meta":
+		meta, err := trainer.LoadRealGameMeta(strings.TrimSuffix(filename, ".meta") + ".input")
+		if err != nil {
+			return "", err
+		}
+		if meta.Strategy != "" {
+			if _, err := trainer.GetStrategy(meta.Strategy); err != nil {
+				return "", fmt.Errorf("%s: %v", filename, err)
+			}
+		}
+		return "настройки real-games файла корректны", nil
+	case ".json", ".yaml", ".yml":
+		if _, err := trainer.LoadConfig(filename); err != nil {
+			return "", err
+		}
+		return "конфигурация корректна", nil
+	default:
+		records, err := trainer.ReadCSV(filename)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("записей %d", len(records)), nil
+	}
+}
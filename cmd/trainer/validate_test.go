@@ -0,0 +1,21 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestValidateBookmakerPrices checks that validate rejects an .input row where
+// no bookmaker prices an outcome
+func TestValidateBookmakerPrices(t *testing.T) {
+	dir := t.TempDir()
+	content := "result,oddF@bc,oddX@bc,oddL@bc\nF,1.80,3.60,4.50\nL,2.00,,4.00\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "lines.input"), []byte(content), 0644))
+
+	output, code := runTrainer(t, dir, "validate", "lines.input")
+	assert.Equal(t, exitData, code, output)
+	assert.Contains(t, output, "no bookmaker price above 1 for oddX in lines.input line 3")
+}
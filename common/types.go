@@ -1,22 +1,154 @@
 package common
 
 import (
-	"bufio"
+	"encoding/csv"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"strconv"
 	"strings"
 )
 
+// Match holds optional metadata of a match from the extended .input format
+type Match struct {
+	Date       string `json:"date,omitempty"`
+	Home       string `json:"home,omitempty"`
+	Away       string `json:"away,omitempty"`
+	League     string `json:"league,omitempty"`
+	Sport      string `json:"sport,omitempty"`
+	BookmakerF string `json:"bookmakerF,omitempty"` // Bookmaker the F stake went to
+	BookmakerX string `json:"bookmakerX,omitempty"`
+	BookmakerL string `json:"bookmakerL,omitempty"`
+	Note       string `json:"note,omitempty"`
+}
+
+// MatchColumns lists the optional .input columns in their canonical order
+var MatchColumns = []string{"date", "home", "away", "league", "sport", "bookmakerF", "bookmakerX", "bookmakerL", "note"}
+
+// Field returns a pointer to the metadata field for a column from MatchColumns
+// (nil for an unknown column)
+func (m *Match) Field(column string) *string {
+	switch column {
+	case "date":
+		return &m.Date
+	case "home":
+		return &m.Home
+	case "away":
+		return &m.Away
+	case "league":
+		return &m.League
+	case "sport":
+		return &m.Sport
+	case "bookmakerF":
+		return &m.BookmakerF
+	case "bookmakerX":
+		return &m.BookmakerX
+	case "bookmakerL":
+		return &m.BookmakerL
+	case "note":
+		return &m.Note
+	}
+	return nil
+}
+
+// IsZero reports whether no metadata is set
+func (m Match) IsZero() bool {
+	return m == Match{}
+}
+
+// String describes the match as "Home - Away (date, league, sport)"
+func (m Match) String() string {
+	title := m.Home
+	if m.Away != "" {
+		if title != "" {
+			title += " - "
+		}
+		title += m.Away
+	}
+	details := []string{}
+	for _, value := range []string{m.Date, m.League, m.Sport} {
+		if value != "" {
+			details = append(details, value)
+		}
+	}
+	if len(details) > 0 {
+		if title != "" {
+			title += " "
+		}
+		title += "(" + strings.Join(details, ", ") + ")"
+	}
+	return title
+}
+
+// BookmakerOdds holds one bookmaker's odds for an event (0 - no price for the outcome)
+type BookmakerOdds struct {
+	Name string  `json:"name"`
+	OddF float64 `json:"oddF"`
+	OddX float64 `json:"oddX"`
+	OddL float64 `json:"oddL"`
+}
+
+// Odd returns the bookmaker's odd for outcome F, X or L
+func (b BookmakerOdds) Odd(outcome string) float64 {
+	switch outcome {
+	case "F":
+		return b.OddF
+	case "X":
+		return b.OddX
+	case "L":
+		return b.OddL
+	}
+	return 0
+}
+
 // Event represents a single event from the input file
 type Event struct {
-	Result string
-	OddF   float64
-	OddX   float64
-	OddL   float64
+	Result     string
+	OddF       float64
+	OddX       float64
+	OddL       float64
+	Match      Match           // Metadata from the optional columns of the extended format
+	Bookmakers []BookmakerOdds // Odds of several bookmakers from oddF@name columns
+}
+
+// quoted reports whether at least one bookmaker prices the outcome above 1
+func quoted(quotes []BookmakerOdds, outcome string) bool {
+	for _, quote := range quotes {
+		if quote.Odd(outcome) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// bookmakerColumn splits a per-bookmaker odds column "oddF@bc" into outcome and bookmaker
+func bookmakerColumn(column string) (outcome, bookmaker string, ok bool) {
+	odd, bookmaker, found := strings.Cut(column, "@")
+	if !found || bookmaker == "" {
+		return "", "", false
+	}
+	switch odd {
+	case "oddF":
+		return "F", bookmaker, true
+	case "oddX":
+		return "X", bookmaker, true
+	case "oddL":
+		return "L", bookmaker, true
+	}
+	return "", "", false
 }
 
-// ReadInputFile reads and parses an .input file
+// ReadInputFile reads and parses an .input file.
+//
+// The header names the columns: result, oddF, oddX and oddL are required,
+// the MatchColumns are optional and may come in any order; unknown columns
+// are ignored. A first line without a "result" column is skipped as before
+// and the four required columns are read by position.
+//
+// Columns "oddF@bc", "oddX@bc", "oddL@bc" hold the odds of bookmaker "bc"
+// (an empty cell means no price). With such columns oddF, oddX and oddL may
+// be omitted: they default to the best price of each outcome.
 func ReadInputFile(filename string) ([]Event, error) {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -24,47 +156,205 @@ func ReadInputFile(filename string) ([]Event, error) {
 	}
 	defer file.Close()
 
-	var events []Event
-	scanner := bufio.NewScanner(file)
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.LazyQuotes = true
 
-	// Skip header line
-	if !scanner.Scan() {
+	var events []Event
+	header, err := reader.Read()
+	if err == io.EOF {
 		return events, nil
 	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid header in %s: %v", filename, err)
+	}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
+	columns := map[string]int{"result": 0, "oddF": 1, "oddX": 2, "oddL": 3}
+	required := []string{"result", "oddF", "oddX", "oddL"}
+	var bookmakers []string // Bookmakers in the order of their first column
+	if containsColumn(header, "result") {
+		columns = map[string]int{}
+		for i, name := range header {
+			name = strings.TrimSpace(name)
+			columns[name] = i
+			if _, bookmaker, ok := bookmakerColumn(name); ok && !containsColumn(bookmakers, bookmaker) {
+				bookmakers = append(bookmakers, bookmaker)
+			}
 		}
-
-		parts := strings.Split(line, ",")
-		if len(parts) != 4 {
-			return nil, fmt.Errorf("invalid line format in %s: %s", filename, line)
+		if len(bookmakers) > 0 {
+			required = []string{"result"}
 		}
+		for _, name := range required {
+			if _, ok := columns[name]; !ok {
+				return nil, fmt.Errorf("missing column %s in %s", name, filename)
+			}
+		}
+	}
+	width := 0
+	for _, name := range required {
+		if columns[name]+1 > width {
+			width = columns[name] + 1
+		}
+	}
+	cell := func(parts []string, column string) (string, bool) {
+		i, ok := columns[column]
+		if !ok || i >= len(parts) {
+			return "", false
+		}
+		return parts[i], true
+	}
 
-		oddF, err := strconv.ParseFloat(parts[1], 64)
+	for {
+		parts, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			return nil, fmt.Errorf("invalid oddF value in %s: %s", filename, parts[1])
+			return nil, fmt.Errorf("invalid line format in %s: %v", filename, err)
+		}
+		if len(parts) == 1 && strings.TrimSpace(parts[0]) == "" {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		if len(parts) < width {
+			return nil, fmt.Errorf("invalid line format in %s line %d: %s", filename, line, strings.Join(parts, ","))
+		}
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
 		}
 
-		oddX, err := strconv.ParseFloat(parts[2], 64)
-		if err != nil {
-			return nil, fmt.Errorf("invalid oddX value in %s: %s", filename, parts[2])
+		event := Event{Result: parts[columns["result"]]}
+		for _, bookmaker := range bookmakers {
+			quote := BookmakerOdds{Name: bookmaker}
+			for _, odd := range []struct {
+				name  string
+				value *float64
+			}{{"oddF@", &quote.OddF}, {"oddX@", &quote.OddX}, {"oddL@", &quote.OddL}} {
+				value, ok := cell(parts, odd.name+bookmaker)
+				if !ok || value == "" {
+					continue
+				}
+				if *odd.value, err = strconv.ParseFloat(value, 64); err != nil {
+					return nil, fmt.Errorf("invalid %s value in %s line %d: %s", odd.name+bookmaker, filename, line, value)
+				}
+			}
+			event.Bookmakers = append(event.Bookmakers, quote)
+		}
+		for _, odd := range []struct {
+			name  string
+			value *float64
+		}{{"oddF", &event.OddF}, {"oddX", &event.OddX}, {"oddL", &event.OddL}} {
+			if len(bookmakers) > 0 && !quoted(event.Bookmakers, odd.name[3:]) {
+				return nil, fmt.Errorf("no bookmaker price above 1 for %s in %s line %d", odd.name, filename, line)
+			}
+			value, ok := cell(parts, odd.name)
+			if !ok && len(bookmakers) > 0 {
+				// Without the column the best bookmaker price is used
+				for _, quote := range event.Bookmakers {
+					*odd.value = math.Max(*odd.value, quote.Odd(odd.name[3:]))
+				}
+				continue
+			}
+			if value == "" && len(bookmakers) > 0 {
+				return nil, fmt.Errorf("empty %s value in %s line %d: fill it or drop the %s column to use the best bookmaker price", odd.name, filename, line, odd.name)
+			}
+			*odd.value, err = strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value in %s line %d: %s", odd.name, filename, line, value)
+			}
+		}
+		for _, name := range MatchColumns {
+			// Trailing empty optional cells may be omitted
+			if i, ok := columns[name]; ok && i < len(parts) {
+				*event.Match.Field(name) = parts[i]
+			}
 		}
 
-		oddL, err := strconv.ParseFloat(parts[3], 64)
-		if err != nil {
-			return nil, fmt.Errorf("invalid oddL value in %s: %s", filename, parts[3])
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func containsColumn(header []string, name string) bool {
+	for _, column := range header {
+		if strings.TrimSpace(column) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteInputFile writes events to an .input file. Metadata columns are
+// written only when at least one event has a value for them.
+func WriteInputFile(filename string, events []Event) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	header := []string{"result", "oddF", "oddX", "oddL"}
+	var bookmakers []string
+	for i := range events {
+		for _, quote := range events[i].Bookmakers {
+			if !containsColumn(bookmakers, quote.Name) {
+				bookmakers = append(bookmakers, quote.Name)
+			}
+		}
+	}
+	for _, bookmaker := range bookmakers {
+		header = append(header, "oddF@"+bookmaker, "oddX@"+bookmaker, "oddL@"+bookmaker)
+	}
+	var metaColumns []string
+	for _, name := range MatchColumns {
+		for i := range events {
+			if *events[i].Match.Field(name) != "" {
+				metaColumns = append(metaColumns, name)
+				break
+			}
 		}
+	}
 
-		events = append(events, Event{
-			Result: parts[0],
-			OddF:   oddF,
-			OddX:   oddX,
-			OddL:   oddL,
-		})
+	writer := csv.NewWriter(file)
+	if err := writer.Write(append(header, metaColumns...)); err != nil {
+		return err
 	}
+	for i := range events {
+		event := &events[i]
+		row := []string{
+			event.Result,
+			strconv.FormatFloat(event.OddF, 'f', -1, 64),
+			strconv.FormatFloat(event.OddX, 'f', -1, 64),
+			strconv.FormatFloat(event.OddL, 'f', -1, 64),
+		}
+		for _, bookmaker := range bookmakers {
+			quote := BookmakerOdds{}
+			for _, q := range event.Bookmakers {
+				if q.Name == bookmaker {
+					quote = q
+				}
+			}
+			for _, outcome := range []string{"F", "X", "L"} {
+				row = append(row, formatOdd(quote.Odd(outcome)))
+			}
+		}
+		for _, name := range metaColumns {
+			row = append(row, *event.Match.Field(name))
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
 
-	return events, scanner.Err()
+// formatOdd writes an odd, leaving the cell empty when there is no price
+func formatOdd(odd float64) string {
+	if odd == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(odd, 'f', -1, 64)
 }
@@ -0,0 +1,327 @@
+// Package journal читает и записывает журнал ставок в формате, который
+// ведется вручную (см. new_line_0000):
+//
+//	366/174/144          счетчики
+//	-7.650.000           общий итог
+//	---Football---       раздел вида спорта
+//	300+157+141          счетчики раздела
+//	--
+//	X/X/L/...            события от новых к старым
+//	Ирак - Индонезия     следующий матч
+//	#60000               итог раздела
+//	F:10000              базовая сумма, от которой рассчитана ставка на исход
+//	11400,bc             ставка и букмекер
+//	...
+//	Результаты:          помесячная ведомость
+//	30.04.24 430000
+package journal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Journal журнал ставок
+type Journal struct {
+	Counts   []int     // Счетчики первой строки ("366/174/144")
+	Balance  float64   // Общий итог ("-7.650.000")
+	Sections []Section // Разделы по видам спорта
+	Results  []Result  // Помесячная ведомость "Результаты:" (от новых к старым)
+}
+
+// Section раздел журнала по виду спорта
+type Section struct {
+	Sport  string   // Вид спорта из заголовка "---Football---"
+	Counts []int    // Счетчики раздела ("300+157+141")
+	Events []string // События F/X/L от новых к старым
+	Match  string   // Матч, на который сделаны ставки
+	Total  float64  // Итог раздела ("#60000")
+	Bets   []Bet    // Ставки на исходы матча
+}
+
+// Bet ставка на исход: блок "F:10000" и строка "11400,bc"
+type Bet struct {
+	Outcome   string  // F, X или L
+	Base      float64 // Базовая сумма, от которой рассчитана ставка (DefaultBetF)
+	Stake     float64 // Сумма ставки
+	Bookmaker string  // Букмекер ("bc", "pm"; пусто - не указан)
+}
+
+// Result итог месяца в ведомости "Результаты:"
+type Result struct {
+	Date   time.Time // Последний день месяца
+	Amount float64
+}
+
+// ResultsHeader заголовок помесячной ведомости
+const ResultsHeader = "Результаты:"
+
+// resultDateLayout формат даты ведомости (30.04.24)
+const resultDateLayout = "02.01.06"
+
+var (
+	sectionPattern = regexp.MustCompile(`^---(.+)---$`)
+	eventsPattern  = regexp.MustCompile(`^[FXLN](/[FXLN])*$`)
+	countsPattern  = regexp.MustCompile(`^\d+([+/]\d+)+$`)
+	amountPattern  = regexp.MustCompile(`^-?\d{1,3}(\.\d{3})+$`)
+	betPattern     = regexp.MustCompile(`^([FXL]):(.+)$`)
+)
+
+// ParseFile читает журнал из файла
+func ParseFile(filename string) (*Journal, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	journal, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return journal, nil
+}
+
+// Parse читает журнал. Ошибки указывают номер строки.
+func Parse(r io.Reader) (*Journal, error) {
+	journal := &Journal{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var section *Section
+	var pending *Bet // Блок "F:10000", ожидающий строку ставки
+	inResults := false
+	line := 0
+	fail := func(format string, args ...interface{}) error {
+		return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+	}
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		if pending != nil {
+			stake, bookmaker, _ := strings.Cut(text, ",")
+			value, err := parseAmount(stake)
+			if err != nil {
+				return nil, fail("invalid stake %q after %s:", text, pending.Outcome)
+			}
+			pending.Stake = value
+			pending.Bookmaker = strings.TrimSpace(bookmaker)
+			section.Bets = append(section.Bets, *pending)
+			pending = nil
+			continue
+		}
+
+		if match := sectionPattern.FindStringSubmatch(text); match != nil {
+			journal.Sections = append(journal.Sections, Section{Sport: match[1]})
+			section = &journal.Sections[len(journal.Sections)-1]
+			inResults = false
+			continue
+		}
+		if text == ResultsHeader {
+			section = nil
+			inResults = true
+			continue
+		}
+
+		switch {
+		case inResults:
+			date, amount, ok := strings.Cut(text, " ")
+			parsedDate, err := time.Parse(resultDateLayout, date)
+			if !ok || err != nil {
+				return nil, fail("invalid result line %q: expected \"dd.mm.yy amount\"", text)
+			}
+			value, err := parseAmount(strings.TrimSpace(amount))
+			if err != nil {
+				return nil, fail("invalid result amount %q", amount)
+			}
+			journal.Results = append(journal.Results, Result{Date: parsedDate, Amount: value})
+
+		case section == nil:
+			// Строки до первого раздела: счетчики и общий итог
+			if countsPattern.MatchString(text) {
+				journal.Counts = parseCounts(text)
+				continue
+			}
+			value, err := parseAmount(text)
+			if err != nil {
+				return nil, fail("unexpected line %q before the first section", text)
+			}
+			journal.Balance = value
+
+		case text == "--":
+		case countsPattern.MatchString(text):
+			section.Counts = parseCounts(text)
+		case eventsPattern.MatchString(text):
+			section.Events = strings.Split(text, "/")
+		case strings.HasPrefix(text, "#"):
+			value, err := parseAmount(strings.TrimPrefix(text, "#"))
+			if err != nil {
+				return nil, fail("invalid total %q", text)
+			}
+			section.Total = value
+		case betPattern.MatchString(text):
+			match := betPattern.FindStringSubmatch(text)
+			value, err := parseAmount(strings.TrimSpace(match[2]))
+			if err != nil {
+				return nil, fail("invalid base amount %q", text)
+			}
+			pending = &Bet{Outcome: match[1], Base: value}
+		default:
+			if section.Match != "" {
+				return nil, fail("unexpected line %q: match already set to %q", text, section.Match)
+			}
+			section.Match = text
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return nil, fmt.Errorf("line %d: missing stake after %s:", line, pending.Outcome)
+	}
+
+	return journal, nil
+}
+
+// parseCounts разбирает счетчики "300+157+141" или "366/174/144"
+func parseCounts(text string) []int {
+	counts := []int{}
+	for _, part := range strings.FieldsFunc(text, func(r rune) bool { return r == '+' || r == '/' }) {
+		value, _ := strconv.Atoi(part)
+		counts = append(counts, value)
+	}
+	return counts
+}
+
+// parseAmount разбирает сумму; точки между группами из трех цифр - разделители
+// тысяч ("-7.650.000")
+func parseAmount(text string) (float64, error) {
+	if amountPattern.MatchString(text) {
+		text = strings.ReplaceAll(text, ".", "")
+	}
+	return strconv.ParseFloat(text, 64)
+}
+
+// formatAmount записывает сумму без дробной части, если она целая
+func formatAmount(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// formatBalance записывает общий итог с точками между тысячами ("-7.650.000")
+func formatBalance(value float64) string {
+	text := formatAmount(value)
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	if strings.Contains(text, ".") || len(text) <= 3 {
+		return sign + text
+	}
+	groups := []string{}
+	for len(text) > 3 {
+		groups = append([]string{text[len(text)-3:]}, groups...)
+		text = text[:len(text)-3]
+	}
+	return sign + text + "." + strings.Join(groups, ".")
+}
+
+func joinCounts(counts []int, separator string) string {
+	parts := make([]string, len(counts))
+	for i, count := range counts {
+		parts[i] = strconv.Itoa(count)
+	}
+	return strings.Join(parts, separator)
+}
+
+// WriteFile записывает журнал в файл
+func WriteFile(filename string, journal *Journal) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return Write(file, journal)
+}
+
+// Write записывает журнал в том же виде, в котором он ведется вручную
+func Write(w io.Writer, journal *Journal) error {
+	out := bufio.NewWriter(w)
+
+	if len(journal.Counts) > 0 {
+		fmt.Fprintln(out, joinCounts(journal.Counts, "/"))
+	}
+	fmt.Fprintln(out, formatBalance(journal.Balance))
+
+	for _, section := range journal.Sections {
+		fmt.Fprintf(out, "---%s---\n", section.Sport)
+		if len(section.Counts) > 0 {
+			fmt.Fprintln(out, joinCounts(section.Counts, "+"))
+		}
+		fmt.Fprintln(out, "--")
+		if len(section.Events) > 0 {
+			fmt.Fprintln(out, strings.Join(section.Events, "/"))
+		}
+		if section.Match != "" {
+			fmt.Fprintln(out, section.Match)
+		}
+		fmt.Fprintf(out, "#%s\n", formatAmount(section.Total))
+		for _, bet := range section.Bets {
+			fmt.Fprintf(out, "%s:%s\n", bet.Outcome, formatAmount(bet.Base))
+			if bet.Bookmaker != "" {
+				fmt.Fprintf(out, "%s,%s\n\n", formatAmount(bet.Stake), bet.Bookmaker)
+			} else {
+				fmt.Fprintf(out, "%s\n\n", formatAmount(bet.Stake))
+			}
+		}
+	}
+
+	if len(journal.Results) > 0 {
+		fmt.Fprintln(out, ResultsHeader)
+		for _, result := range journal.Results {
+			fmt.Fprintf(out, "%s %s\n", result.Date.Format(resultDateLayout), formatAmount(result.Amount))
+		}
+	}
+
+	return out.Flush()
+}
+
+// Section возвращает раздел вида спорта (без учета регистра)
+func (j *Journal) Section(sport string) (*Section, bool) {
+	for i := range j.Sections {
+		if strings.EqualFold(j.Sections[i].Sport, sport) {
+			return &j.Sections[i], true
+		}
+	}
+	return nil, false
+}
+
+// EventsFromOldest возвращает события раздела от старых к новым
+func (s Section) EventsFromOldest() []string {
+	events := make([]string, len(s.Events))
+	for i, event := range s.Events {
+		events[len(events)-1-i] = event
+	}
+	return events
+}
+
+// Bet возвращает ставку на исход
+func (s Section) Bet(outcome string) (Bet, bool) {
+	for _, bet := range s.Bets {
+		if bet.Outcome == outcome {
+			return bet, true
+		}
+	}
+	return Bet{}, false
+}
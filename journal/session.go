@@ -0,0 +1,85 @@
+package journal
+
+import (
+	"strings"
+
+	"github.com/holygun/go-trainer/common"
+	"github.com/holygun/go-trainer/trainer"
+)
+
+// Hockey сообщает, относится ли раздел к хоккею
+func (s Section) Hockey() bool {
+	return strings.EqualFold(s.Sport, "hockey")
+}
+
+// MatchOdds возвращает коэффициенты матча раздела - события, следующего за
+// событиями журнала, - из provider (например, .input файла с теми же событиями
+// и строкой матча с результатом N). ok = false, если provider их не знает.
+func (s Section) MatchOdds(provider trainer.OddsProvider) (trainer.Odds, bool) {
+	fixed, ok := provider.(*trainer.FixedOddsProvider)
+	if !ok {
+		return trainer.Odds{}, false
+	}
+	return fixed.Provided(len(s.Events) + 1)
+}
+
+// NewSession создает сессию советника по событиям раздела. Коэффициентов
+// прошлых событий в журнале нет: их отдает provider (например, .input файл
+// с теми же событиями), а при nil они генерируются по flags.Seed.
+func NewSession(section Section, strategy trainer.Strategy, flags trainer.Flags, provider trainer.OddsProvider) *trainer.Session {
+	flags.Hockey = section.Hockey()
+	if provider == nil {
+		provider = trainer.NewRandomOddsProvider(flags.Seed, flags)
+	}
+
+	events := []common.Event{}
+	for i, result := range section.EventsFromOldest() {
+		odds := provider.Odds(i + 1)
+		events = append(events, common.Event{Result: result, OddF: odds.OddF, OddX: odds.OddX, OddL: odds.OddL})
+	}
+	return trainer.NewSession(strategy, flags, events)
+}
+
+// Mismatch расхождение ставки журнала с расчетом стратегии
+type Mismatch struct {
+	Outcome  string
+	Journal  float64
+	Strategy float64
+}
+
+// Check сравнивает записанные вручную ставки раздела со ставками решения
+// стратегии. Базовые суммы блоков ("F:10000") не сравниваются: это не убытки
+// решения, а сумма, от которой они рассчитаны.
+func Check(section Section, decision trainer.BetDecision) []Mismatch {
+	var mismatches []Mismatch
+	for _, outcome := range trainer.Outcomes {
+		bet, _ := section.Bet(outcome)
+		if stake := decision.Bets.Get(outcome); bet.Stake != stake {
+			mismatches = append(mismatches, Mismatch{Outcome: outcome, Journal: bet.Stake, Strategy: stake})
+		}
+	}
+	return mismatches
+}
+
+// Sheet возвращает раздел со ставками решения стратегии на матч match.
+// Базовые суммы и букмекеры исходов берутся из раздела (без блока исхода -
+// base), события и счетчики не меняются.
+func Sheet(section Section, decision trainer.BetDecision, match string, base float64) Section {
+	sheet := section
+	sheet.Match = match
+	sheet.Total = decision.Total
+	sheet.Bets = nil
+	for _, outcome := range trainer.Outcomes {
+		previous, found := section.Bet(outcome)
+		if !found {
+			previous.Base = base
+		}
+		sheet.Bets = append(sheet.Bets, Bet{
+			Outcome:   outcome,
+			Base:      previous.Base,
+			Stake:     decision.Bets.Get(outcome),
+			Bookmaker: previous.Bookmaker,
+		})
+	}
+	return sheet
+}
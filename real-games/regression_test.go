@@ -3,6 +3,7 @@ package tests
 import (
     "flag"
     "fmt"
+    "os"
     "path/filepath"
     "strings"
     "testing"
@@ -48,6 +49,16 @@ func TestRegressionSuite(t *testing.T) {
             }
             strategyName := parts[0]
 
+            // Per-file settings from the optional .meta sidecar
+            meta, err := trainer.LoadRealGameMeta(testFile)
+            if err != nil {
+                t.Fatalf("Failed to read meta for %s: %v", testFile, err)
+            }
+            settings, err := meta.Resolve(strategyName, isHockey, trainer.DefaultConfig())
+            if err != nil {
+                t.Fatalf("Failed to apply meta for %s: %v", testFile, err)
+            }
+
             // Construct paths for .input and results files
             inputPath := testFile
             resultsPath := strings.TrimSuffix(testFile, ".input") + suffixResults
@@ -92,11 +103,16 @@ func TestRegressionSuite(t *testing.T) {
                 Verbose:  false,
                 Debug:    *debug,
                 Report:   "",
-                Hockey:   isHockey,
-                Strategy: strategyName,
-                Real:     false,
+                Hockey:   settings.Hockey,
+                Strategy: settings.Strategy.Name(),
+                Real:     !isExpectedResults, // .actual files are written by trainer real
                 Force:    false,
-                Testing:  true,
+                Testing:  isExpectedResults,
+                Config:   &settings.Config,
+            }
+            if *debug {
+                flags.Bus = trainer.NewEventBus()
+                flags.Bus.Subscribe(trainer.NewConsoleSink(os.Stdout), trainer.LevelDebug)
             }
             actualOutput, err := processInputEvents(inputEvents, flags, isExpectedResults)
             if err != nil {
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// simulateWithBankroll runs xlDrop over F/L/L/F at fixed odds with the given bankroll settings
+func simulateWithBankroll(t *testing.T, bankroll trainer.BankrollConfig) []trainer.TrainerRecord {
+	strategy, err := trainer.GetStrategy("xlDrop")
+	assert.NoError(t, err)
+	cfg := trainer.DefaultConfig()
+	cfg.Bankroll = bankroll
+	assert.NoError(t, cfg.Validate())
+	flags := trainer.Flags{Strategy: strategy.Name(), Testing: true, Config: &cfg}
+	odds := trainer.Odds{OddF: 2, OddX: 3.5, OddL: 4}
+	provider := trainer.NewFixedOddsProvider([]trainer.Odds{odds, odds, odds, odds}, nil, flags)
+	return trainer.Simulate([]string{"F", "L", "L", "F"}, provider, flags, strategy)
+}
+
+// TestStakeLimits checks each on_cap policy when the planned L bet of event 3
+// (7350) exceeds the limit, the stake cap and ruin detection
+func TestStakeLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		bankroll trainer.BankrollConfig
+		events   int                // Records before the run ends
+		limit    string             // Limit of event 3
+		bets     trainer.PerOutcome // Bets of event 3
+		lossL    float64            // Loss L after event 3 (L won)
+		total    float64            // Final total
+	}{
+		{"no limits", trainer.BankrollConfig{OnCap: trainer.OnCapStop},
+			4, "", trainer.PerOutcome{F: 10000, X: 6100, L: 7350}, 0, 40000},
+		{"stop", trainer.BankrollConfig{MaxBet: trainer.PerOutcome{L: 6000}, OnCap: trainer.OnCapStop},
+			3, trainer.OnCapStop, trainer.PerOutcome{F: 10000, X: 6100, L: 7350}, 22000, 20000},
+		// The 4000 the capped bet does not win back stays on L
+		{"clamp", trainer.BankrollConfig{MaxBet: trainer.PerOutcome{L: 6000}, OnCap: trainer.OnCapClamp},
+			4, trainer.OnCapClamp, trainer.PerOutcome{F: 10000, X: 6100, L: 6000}, 4000, 40000},
+		// ... or is written off the total
+		{"writeoff", trainer.BankrollConfig{MaxBet: trainer.PerOutcome{L: 6000}, OnCap: trainer.OnCapWriteOff},
+			4, trainer.OnCapWriteOff, trainer.PerOutcome{F: 10000, X: 6100, L: 6000}, 0, 29150},
+		// Bets are scaled down to the stake cap and rounded down
+		{"max stake", trainer.BankrollConfig{MaxStake: 20000, OnCap: trainer.OnCapClamp},
+			4, trainer.OnCapClamp, trainer.PerOutcome{F: 8500, X: 5200, L: 6250}, 3250, 40000},
+		// 23450 of bets with 22900 left in the bankroll
+		{"ruin", trainer.BankrollConfig{Capital: 20000, OnCap: trainer.OnCapStop},
+			3, trainer.LimitRuin, trainer.PerOutcome{F: 10000, X: 6100, L: 7350}, 22000, 20000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := simulateWithBankroll(t, tt.bankroll)
+			assert.Len(t, records, tt.events)
+			event := records[2]
+			assert.Equal(t, tt.limit, event.Limit)
+			assert.Equal(t, tt.bets, trainer.PerOutcome{F: event.BetF, X: event.BetX, L: event.BetL})
+			assert.Equal(t, tt.lossL, event.LossL)
+			assert.Equal(t, tt.total, records[len(records)-1].Total)
+			assert.Equal(t, tt.events == 3, records[len(records)-1].Stopped())
+			for _, record := range records {
+				if tt.bankroll.MaxStake > 0 {
+					assert.LessOrEqual(t, record.BetF+record.BetX+record.BetL, tt.bankroll.MaxStake)
+				}
+				if maxBet := tt.bankroll.MaxBet.L; maxBet > 0 && !record.Stopped() {
+					assert.LessOrEqual(t, record.BetL, maxBet)
+				}
+			}
+		})
+	}
+}
+
+// TestBankrollAccounting checks that every bet is paid from the bankroll and
+// the winning bet returns with its odd, on a win and on a loss
+func TestBankrollAccounting(t *testing.T) {
+	records := simulateWithBankroll(t, trainer.BankrollConfig{Capital: 100000, OnCap: trainer.OnCapStop})
+	assert.Len(t, records, 4)
+
+	// Event 1, F wins: 100000 - (10000+4000+3350) + 10000*2
+	assert.Equal(t, 102650.0, records[0].Bankroll)
+	// Event 2, L wins: 102650 - (10000+4900+5050) + 5050*4
+	assert.Equal(t, 102900.0, records[1].Bankroll)
+	// Event 3, L wins: 102900 - (10000+6100+7350) + 7350*4
+	assert.Equal(t, 108850.0, records[2].Bankroll)
+	// Event 4, F wins less than staked: 108850 - (10000+6600+8300) + 10000*2
+	assert.Equal(t, 103950.0, records[3].Bankroll)
+	for _, record := range records {
+		assert.Empty(t, record.Limit)
+	}
+}
+
+// TestBankrollStats checks that limits without a capital are counted without
+// reporting a bankroll and that a capital gives the final and minimum bankroll
+func TestBankrollStats(t *testing.T) {
+	records := simulateWithBankroll(t, trainer.BankrollConfig{MaxBet: trainer.PerOutcome{L: 5000}, OnCap: trainer.OnCapClamp})
+	stats := trainer.CalculateStats(records, []string{"F", "L", "L", "F"})
+	assert.True(t, stats.HasLimits)
+	assert.False(t, stats.HasBankroll)
+	assert.Equal(t, 3, stats.CapHits)
+	assert.Zero(t, stats.FinalBankroll)
+
+	records = simulateWithBankroll(t, trainer.BankrollConfig{Capital: 100000, OnCap: trainer.OnCapStop})
+	stats = trainer.CalculateStats(records, []string{"F", "L", "L", "F"})
+	assert.False(t, stats.HasLimits)
+	assert.True(t, stats.HasBankroll)
+	assert.Equal(t, 103950.0, stats.FinalBankroll)
+	assert.Equal(t, 102650.0, stats.MinBankroll)
+}
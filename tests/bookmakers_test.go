@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holygun/go-trainer/common"
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBookmakerLineSelection checks best-price selection per outcome, the
+// preferred bookmaker tolerance and the line-shopping value in records
+func TestBookmakerLineSelection(t *testing.T) {
+	quotes := []common.BookmakerOdds{
+		{Name: "bc", OddF: 2.00, OddX: 3.40, OddL: 4.00},
+		{Name: "pm", OddF: 2.05, OddX: 3.30, OddL: 4.20},
+	}
+
+	choice := trainer.SelectLine(quotes, trainer.BookmakerConfig{})
+	assert.Equal(t, trainer.Odds{OddF: 2.05, OddX: 3.40, OddL: 4.20}, choice.Odds)
+	assert.Equal(t, map[string]string{"F": "pm", "X": "bc", "L": "pm"}, choice.Bookmakers)
+
+	// The preferred bookmaker wins when it is within tolerance of the best price
+	choice = trainer.SelectLine(quotes, trainer.BookmakerConfig{Preferred: "bc", Tolerance: 0.03})
+	assert.Equal(t, trainer.Odds{OddF: 2.00, OddX: 3.40, OddL: 4.20}, choice.Odds)
+	assert.Equal(t, map[string]string{"F": "bc", "X": "bc", "L": "pm"}, choice.Bookmakers)
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "lines.input")
+	content := "date,home,away,result,oddF@bc,oddX@bc,oddL@bc,oddF@pm,oddX@pm,oddL@pm\n" +
+		"2024-06-11,Ирак,Индонезия,L,2.00,3.40,4.00,2.05,3.30,4.20\n" +
+		"2024-06-12,Япония,Сирия,F,1.80,3.60,4.50,1.85,3.50,4.40\n"
+	assert.NoError(t, os.WriteFile(input, []byte(content), 0644))
+
+	events, err := common.ReadInputFile(input)
+	assert.NoError(t, err)
+	assert.Len(t, events[0].Bookmakers, 2)
+	assert.Equal(t, 4.20, events[0].OddL)
+
+	strategy, err := trainer.GetStrategy("xlDrop")
+	assert.NoError(t, err)
+	cfg := trainer.DefaultConfig()
+	cfg.Bookmakers = trainer.BookmakerConfig{Baseline: "bc"}
+	records := trainer.GenerateRecordsFromEvents(events, trainer.Flags{Strategy: strategy.Name(), Config: &cfg}, strategy)
+	assert.Equal(t, "pm", records[0].Match.BookmakerL)
+	assert.Equal(t, "bc", records[0].Match.BookmakerX)
+	assert.Equal(t, 4.20, records[0].OddL)
+	assert.InDelta(t, records[0].BetL*(4.20-4.00), records[0].LineValue, 1e-9)
+
+	turnover := trainer.BookmakerTurnover(records)
+	total := 0.0
+	for _, record := range records {
+		total += record.BetF + record.BetX + record.BetL
+	}
+	assert.InDelta(t, total, turnover["bc"]+turnover["pm"], 1e-9)
+
+	// Per-bookmaker columns survive a write/read round trip
+	output := filepath.Join(dir, "copy.input")
+	assert.NoError(t, common.WriteInputFile(output, events))
+	copied, err := common.ReadInputFile(output)
+	assert.NoError(t, err)
+	assert.Equal(t, events[1].Bookmakers, copied[1].Bookmakers)
+}
+
+// TestBookmakerMissingPrices checks that a row with no price above 1 for an
+// outcome and an empty oddF cell next to bookmaker columns are rejected
+func TestBookmakerMissingPrices(t *testing.T) {
+	// A missing or non-positive quote is never selected
+	choice := trainer.SelectLine([]common.BookmakerOdds{
+		{Name: "bc", OddF: 2.00, OddX: 0, OddL: 4.00},
+		{Name: "pm", OddF: 2.05, OddX: 3.30, OddL: 1},
+	}, trainer.BookmakerConfig{Preferred: "pm", Tolerance: 1})
+	assert.Equal(t, trainer.Odds{OddF: 2.05, OddX: 3.30, OddL: 4.00}, choice.Odds)
+
+	dir := t.TempDir()
+	cases := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"no X price", "result,oddF@bc,oddX@bc,oddL@bc,oddF@pm,oddX@pm,oddL@pm\nL,2.00,,4.00,2.05,,4.20\n",
+			"no bookmaker price above 1 for oddX in"},
+		{"only price of 1", "result,oddF@bc,oddX@bc,oddL@bc\nF,1.80,3.60,1.00\n",
+			"no bookmaker price above 1 for oddL in"},
+		{"empty oddF cell", "result,oddF,oddX,oddL,oddF@bc,oddX@bc,oddL@bc\nF,,3.60,4.50,1.80,3.60,4.50\n",
+			"empty oddF value in"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := filepath.Join(dir, "missing.input")
+			assert.NoError(t, os.WriteFile(input, []byte(tc.content), 0644))
+			_, err := common.ReadInputFile(input)
+			assert.ErrorContains(t, err, tc.err)
+			assert.ErrorContains(t, err, "line 2")
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadConfig checks that JSON and YAML configs load to the same values over
+// the defaults and that unknown keys and inconsistent values are rejected
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+		return filename
+	}
+
+	fromJSON, err := trainer.LoadConfig(write("config.json", `{"default_bet": 5000, "odds_ranges": {"odd_f": {"min": 1.7, "max": 2.2}}}`))
+	assert.NoError(t, err)
+	fromYAML, err := trainer.LoadConfig(write("config.yaml", "default_bet: 5000\nodds_ranges:\n  odd_f: {min: 1.7, max: 2.2}\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, fromJSON, fromYAML)
+	assert.Equal(t, 5000.0, fromYAML.DefaultBetF)
+	assert.Equal(t, trainer.Range{Min: 1.7, Max: 2.2}, fromYAML.OddsRanges.OddF)
+	// Keys missing from the file keep their defaults
+	assert.Equal(t, trainer.DefaultConfig().RoundUp, fromYAML.RoundUp)
+	assert.Equal(t, trainer.DefaultConfig().OddsRanges.OddX, fromYAML.OddsRanges.OddX)
+
+	// File name, content and a fragment of the expected error
+	invalid := []struct{ name, content, err string }{
+		{"unknown.json", `{"default_bat": 5000}`, `unknown field "default_bat"`},
+		{"unknown.yaml", "round_upp: 100\n", "round_upp"},
+		{"minmax.yaml", "odds_ranges:\n  odd_x: {min: 3.9, max: 3.3}\n", "odds_ranges.odd_x: min 3.9 must be less than max 3.3"},
+		{"odd.yaml", "odds_ranges:\n  odd_f: {min: 0.9, max: 2.1}\n", "must be greater than 1"},
+		{"margin.yaml", "odds_ranges:\n  margin: {min: 1.5, max: 1.6}\n", "unreachable"},
+		{"bet.json", `{"default_bet": 0}`, "default_bet must be positive"},
+		{"config.toml", "default_bet = 5000\n", "unsupported config format"},
+		{"thresholds.yaml", "patterns: {small_mult: 30, big_mult: 20}\n", "small_mult <= big_mult"},
+		{"strategies.yaml", "strategies:\n  xlDrop: {ratio: 2}\n", "out of range"},
+		{"reactions.yaml", "reactions:\n  xlDrop:\n    PURPLE: {policy: writeoff}\n", "PURPLE"},
+		{"tags.yaml", "tags: [bad-tag]\n", "invalid tag"},
+		{"bankroll.yaml", "bankroll: {on_cap: retry}\n", "on_cap"},
+	}
+	for _, tt := range invalid {
+		_, err := trainer.LoadConfig(write(tt.name, tt.content))
+		assert.ErrorContains(t, err, tt.err, tt.name)
+	}
+
+	_, err = trainer.LoadConfig(write("margin-ok.yaml", "odds_ranges:\n  margin: {min: 1.01, max: 1.2}\n"))
+	assert.NoError(t, err)
+}
+
+// TestConfigExample checks that config.example.yaml loads, validates and
+// matches the defaults
+func TestConfigExample(t *testing.T) {
+	cfg, err := trainer.LoadConfig("../config.example.yaml")
+	assert.NoError(t, err)
+	assert.NoError(t, cfg.Validate())
+
+	defaults := trainer.DefaultConfig()
+	assert.Equal(t, defaults.DefaultBetF, cfg.DefaultBetF)
+	assert.Equal(t, defaults.RoundUp, cfg.RoundUp)
+	assert.Equal(t, defaults.OddsRanges, cfg.OddsRanges)
+	assert.Equal(t, defaults.Bankroll.OnCap, cfg.Bankroll.OnCap)
+
+	// The example sets no reactions, so the RED write-off of each strategy
+	// follows its strategies parameter
+	previous := &trainer.TrainerRecord{Pattern: "RED", BetF: 10000}
+	for name, want := range map[string]string{"xlDrop": "RED:writeoff(0.5)", "xlWithSupport": "RED:writeoff(1)"} {
+		strategy, err := trainer.GetStrategy(name)
+		assert.NoError(t, err)
+		assert.Equal(t, want, trainer.React(strategy, previous, 1000000, 1000000, cfg).Applied, name)
+	}
+	xlDrop, err := trainer.GetStrategy("xlDrop")
+	assert.NoError(t, err)
+	cfg.SetParam("xlDrop", "redWriteOff", 0.25)
+	state := trainer.React(xlDrop, previous, 1000000, 1000000, cfg)
+	assert.Equal(t, "RED:writeoff(0.25)", state.Applied)
+	assert.Equal(t, 750000.0, state.RealLoss)
+}
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDecodeCSVVersions checks that legacy positional files, reordered headers
+// with unknown columns and bad cells are handled
+func TestDecodeCSVVersions(t *testing.T) {
+	// Version 1: no meta line and no header, columns by position
+	legacy := "2,X,1.90,3.40,4.20,100,200,300,0,0,0,500,1,0,1,\n"
+	records, err := trainer.DecodeCSV(strings.NewReader(legacy), "legacy.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, records[0].EventNumber)
+	assert.Equal(t, 3.40, records[0].OddX)
+
+	// Newer file: columns in another order plus a column this version does not know
+	newer := "# schema=3 strategy=xlDrop\n" +
+		"pattern,event_number,result,source,oddF,oddX,oddL,betF,betX,betL,lossF,lossX,lossL,total,uf,ux,ul,reaction\n" +
+		"RED,7,F,bookie,1.90,3.40,4.20,100,200,300,0,10,20,500,0,1,1,RED:writeoff(0.5)\n"
+	records, err = trainer.DecodeCSV(strings.NewReader(newer), "newer.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, records[0].EventNumber)
+	assert.Equal(t, "RED", records[0].Pattern)
+	assert.Equal(t, 20.0, records[0].LossL)
+	assert.Equal(t, "RED:writeoff(0.5)", records[0].Reaction)
+
+	// A bad cell is reported with file, line and column
+	broken := strings.Replace(newer, ",3.40,", ",abc,", 1)
+	_, err = trainer.DecodeCSV(strings.NewReader(broken), "broken.csv")
+	var csvErr *trainer.CSVError
+	assert.True(t, errors.As(err, &csvErr))
+	assert.Equal(t, 3, csvErr.Line)
+	assert.Equal(t, 6, csvErr.Column)
+	assert.Contains(t, err.Error(), "broken.csv:3: column 6 (oddX)")
+
+	// Version 2 files must have a header with all base columns
+	_, err = trainer.DecodeCSV(strings.NewReader("# schema=2\n"+legacy), "noheader.csv")
+	assert.ErrorContains(t, err, "noheader.csv:2: missing header")
+	_, err = trainer.DecodeCSV(strings.NewReader("event_number,result\n1,F\n"), "short.csv")
+	assert.ErrorContains(t, err, `missing required column "oddF"`)
+}
+
+// TestCSVMetaQuoting checks that meta values with spaces, quotes or nothing at
+// all survive a write/read round trip and plain values stay unquoted
+func TestCSVMetaQuoting(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "run.csv")
+	meta := map[string]string{
+		"odds_from": filepath.Join(dir, "my odds", "last season.input"),
+		"config":    `C:\Users\me\"quoted" config.yaml`,
+		"note":      "",
+		"strategy":  "xlDrop",
+	}
+	records := []trainer.TrainerRecord{{EventNumber: 1, Result: "F", OddF: 1.9, OddX: 3.4, OddL: 4.2, Total: 10000}}
+	assert.NoError(t, trainer.SaveToCSVWithOptions(records, filename, trainer.CSVOptions{Meta: meta}))
+
+	read, err := trainer.ReadCSVMeta(filename)
+	assert.NoError(t, err)
+	for key, value := range meta {
+		assert.Equal(t, value, read[key], key)
+	}
+	assert.Equal(t, "2", read["schema"])
+
+	data, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), " strategy=xlDrop")
+	assert.Contains(t, string(data), ` note=""`)
+
+	loaded, err := trainer.ReadCSV(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, 10000.0, loaded[0].Total)
+
+	_, err = trainer.DecodeCSV(strings.NewReader("# config=\"unterminated\nevent_number\n"), "bad.csv")
+	assert.ErrorContains(t, err, "invalid quoted value of config")
+}
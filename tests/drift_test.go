@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDrift checks that recomputing an unchanged .input reports no drift and
+// that a changed odd is reported at the first diverging event
+func TestDrift(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "xlDrop.input")
+	assert.NoError(t, os.WriteFile(input, []byte("result,oddF,oddX,oddL\nL,1.83,3.41,4.23\nF,1.83,3.63,4.21\nX,1.9,3.5,4.0\n"), 0644))
+	assert.Equal(t, "", trainer.StoredResultsPath(input))
+
+	records, strategy, err := trainer.RecomputeRealGame(input, trainer.DefaultConfig(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "xlDrop", strategy.Name())
+	actual := filepath.Join(dir, "xlDrop.actual")
+	assert.NoError(t, trainer.SaveToCSVWithOptions(records, actual, trainer.CSVOptions{}))
+	assert.Equal(t, actual, trainer.StoredResultsPath(input))
+
+	stored, err := trainer.ReadCSV(actual)
+	assert.NoError(t, err)
+	drift := trainer.CompareRecords(stored, records)
+	assert.False(t, drift.HasDrift())
+	assert.Equal(t, drift.OldTotal, drift.NewTotal)
+
+	assert.NoError(t, os.WriteFile(input, []byte("result,oddF,oddX,oddL\nL,1.83,3.41,4.23\nF,1.95,3.63,4.21\nX,1.9,3.5,4.0\n"), 0644))
+	records, _, err = trainer.RecomputeRealGame(input, trainer.DefaultConfig(), nil)
+	assert.NoError(t, err)
+	drift = trainer.CompareRecords(stored, records)
+	assert.True(t, drift.HasDrift())
+	assert.Equal(t, 2, drift.FirstEvent)
+	assert.Contains(t, drift.Fields, trainer.DriftField{Name: "oddF", Old: "1.83", New: "1.95"})
+
+	// Events missing from the stored file are drift too
+	drift = trainer.CompareRecords(stored[:1], records)
+	assert.Equal(t, len(records)-1, drift.Changed)
+}
+
+// TestDriftRecordedParams checks that the recomputation uses the parameters
+// recorded in the stored file and that explicit parameters override them
+func TestDriftRecordedParams(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "xlDrop.input")
+	assert.NoError(t, os.WriteFile(input, []byte("result,oddF,oddX,oddL\nL,1.83,3.41,4.23\nX,1.9,3.5,4.0\nF,1.83,3.63,4.21\n"), 0644))
+
+	// Stored file computed with a non-default ratio, as trainer real -param writes it
+	strategy, err := trainer.GetStrategy("xlDrop")
+	assert.NoError(t, err)
+	cfg := trainer.DefaultConfig()
+	cfg.SetParam(strategy.Name(), "ratio", 0.6)
+	events, err := trainer.ReadInputFile(input)
+	assert.NoError(t, err)
+	flags := trainer.Flags{Strategy: strategy.Name(), Real: true, Config: &cfg}
+	records := trainer.GenerateRecordsFromEvents(events, flags, strategy)
+	actual := filepath.Join(dir, "xlDrop.actual")
+	assert.NoError(t, trainer.SaveToCSVWithOptions(records, actual, trainer.CSVOptions{Meta: trainer.ParamsMeta(strategy, cfg)}))
+
+	meta, err := trainer.ReadCSVMeta(actual)
+	assert.NoError(t, err)
+	recorded, err := trainer.RecordedParams(strategy, meta)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.6, recorded["ratio"])
+	_, err = trainer.RecordedParams(strategy, map[string]string{"xlDrop.ratio": "abc", "other.ratio": "1"})
+	assert.ErrorContains(t, err, "xlDrop.ratio")
+
+	stored, err := trainer.ReadCSV(actual)
+	assert.NoError(t, err)
+	recomputed, _, err := trainer.RecomputeRealGame(input, trainer.DefaultConfig(), nil)
+	assert.NoError(t, err)
+	assert.False(t, trainer.CompareRecords(stored, recomputed).HasDrift())
+
+	recomputed, _, err = trainer.RecomputeRealGame(input, trainer.DefaultConfig(), trainer.Params{"ratio": 0.3})
+	assert.NoError(t, err)
+	assert.True(t, trainer.CompareRecords(stored, recomputed).HasDrift())
+
+	_, _, err = trainer.RecomputeRealGame(input, trainer.DefaultConfig(), trainer.Params{"ratio": 5})
+	assert.Error(t, err)
+}
+
+// TestDriftRealGames checks that every committed real-games input has its
+// stored results next to it and recomputes without drift
+func TestDriftRealGames(t *testing.T) {
+	inputs, err := filepath.Glob("../real-games/*.input")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, inputs)
+	for _, input := range inputs {
+		stored := trainer.StoredResultsPath(input)
+		if !assert.NotEmpty(t, stored, input) {
+			continue
+		}
+		records, _, err := trainer.RecomputeRealGame(input, trainer.DefaultConfig(), nil)
+		assert.NoError(t, err)
+		storedRecords, err := trainer.ReadCSV(stored)
+		assert.NoError(t, err)
+		drift := trainer.CompareRecords(storedRecords, records)
+		assert.False(t, drift.HasDrift(), "%s: first event %d %v", input, drift.FirstEvent, drift.Fields)
+	}
+}
@@ -0,0 +1,29 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCalculateDrawdown checks drawdown, time under water and recovery on a known equity curve
+func TestCalculateDrawdown(t *testing.T) {
+	values := []float64{100, 50, -20, 80, 120, 90, 110}
+	curve := make([]trainer.EquityPoint, len(values))
+	for i, value := range values {
+		curve[i] = trainer.EquityPoint{EventNumber: i + 1, Equity: value}
+	}
+
+	dd := trainer.CalculateDrawdown(curve)
+
+	assert.Equal(t, 110.0, dd.FinalEquity)
+	assert.Equal(t, -20.0, dd.MinEquity)
+	assert.Equal(t, 120.0, dd.PeakEquity)
+	assert.Equal(t, 120.0, dd.MaxDrawdown)
+	assert.Equal(t, 3, dd.MaxDrawdownAt)
+	assert.Equal(t, 5, dd.UnderwaterEvents)
+	assert.Equal(t, 3, dd.LongestRecovery)
+	assert.Equal(t, 2, dd.Unrecovered)
+}
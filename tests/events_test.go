@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingSink collects events delivered by the bus
+type recordingSink struct {
+	events []trainer.TrainerEvent
+}
+
+func (s *recordingSink) Handle(event trainer.TrainerEvent) {
+	s.events = append(s.events, event)
+}
+
+// TestEventBusLevels checks that subscribers only get events at or above their level
+func TestEventBusLevels(t *testing.T) {
+	events := trainer.ReverseSlice(trainer.ParseEvents("X/L/X/L/X/L/X/L/X/L/F/X/L"))
+	info, debug := &recordingSink{}, &recordingSink{}
+	bus := trainer.NewEventBus()
+	bus.Subscribe(info, trainer.LevelInfo)
+	bus.Subscribe(debug, trainer.LevelDebug)
+
+	strategy, err := trainer.GetStrategy("xlDrop")
+	assert.NoError(t, err)
+	flags := trainer.Flags{Strategy: strategy.Name(), Seed: 1, Bus: bus}
+	records := trainer.GenerateRecords(events, flags, strategy)
+
+	kinds := map[string]int{}
+	for _, event := range info.events {
+		assert.GreaterOrEqual(t, event.Level(), trainer.LevelInfo)
+		kinds[event.Kind()]++
+	}
+	assert.Equal(t, len(records), kinds["settled"])
+	assert.Equal(t, 0, kinds["bet_decided"])
+	assert.Greater(t, len(debug.events), len(info.events))
+
+	// A nil bus keeps the library silent
+	flags.Bus = nil
+	assert.Equal(t, records, trainer.GenerateRecords(events, flags, strategy))
+}
@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestImportFootballData checks result mapping, bookmaker choice, filters and date ordering
+func TestImportFootballData(t *testing.T) {
+	dir := t.TempDir()
+	season := filepath.Join(dir, "E0.csv")
+	content := "Div,Date,HomeTeam,AwayTeam,FTR,B365H,B365D,B365A,BbAvH,BbAvD,BbAvA\n" +
+		"E0,17/08/19,Arsenal,Burnley,H,1.3,5.5,11,1.31,5.4,10\n" +
+		"E0,10/08/19,West Ham,Man City,A,12,6.5,1.22,11.5,6.6,1.22\n" +
+		"E0,11/08/19,Newcastle,Arsenal,D,,,,4.8,3.9,1.8\n" +
+		"E1,11/08/19,Leeds,Hull,H,1.6,4,5.5,1.6,4,5.5\n"
+	assert.NoError(t, os.WriteFile(season, []byte(content), 0644))
+
+	imported, err := trainer.ImportFootballData([]string{season}, trainer.FootballDataOptions{Leagues: []string{"e0"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, imported.Skipped, "match without B365 odds")
+	assert.Len(t, imported.Events, 2)
+	assert.Equal(t, "L", imported.Events[0].Result, "sorted by date: West Ham - Man City first")
+	assert.Equal(t, 1.22, imported.Events[0].OddL)
+	assert.Equal(t, "2019-08-17", imported.Events[1].Match.Date)
+
+	// Averages of old files, one team, date range
+	imported, err = trainer.ImportFootballData([]string{season}, trainer.FootballDataOptions{
+		Bookmaker: "Avg",
+		Teams:     []string{"Arsenal"},
+		From:      time.Date(2019, 8, 11, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, imported.Events, 2)
+	assert.Equal(t, "X", imported.Events[0].Result)
+	assert.Equal(t, 4.8, imported.Events[0].OddF)
+	assert.Equal(t, "Avg", imported.Events[0].Match.BookmakerX)
+
+	_, err = trainer.ImportFootballData([]string{season}, trainer.FootballDataOptions{Bookmaker: "PS"})
+	assert.ErrorContains(t, err, "no odds columns for bookmaker PS")
+}
@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holygun/go-trainer/common"
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExtendedInputMetadata checks that match metadata from the extended
+// .input format reaches the records and the output CSV
+func TestExtendedInputMetadata(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "matches.input")
+	content := "date,home,away,result,oddF,oddX,oddL,league,bookmakerF,note\n" +
+		"2024-06-11,Ирак,Индонезия,F,1.50,4.20,6.50,WC qualification,pinnacle,\"late goal, 88'\"\n" +
+		"2024-06-12,Япония,Сирия,X,1.20,6.00,13.00\n"
+	assert.NoError(t, os.WriteFile(input, []byte(content), 0644))
+
+	events, err := common.ReadInputFile(input)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, 4.20, events[0].OddX)
+	assert.Equal(t, "Ирак - Индонезия (2024-06-11, WC qualification)", events[0].Match.String())
+	assert.Equal(t, "late goal, 88'", events[0].Match.Note)
+	assert.Equal(t, "Сирия", events[1].Match.Away)
+
+	strategy, err := trainer.GetStrategy("xlDrop")
+	assert.NoError(t, err)
+	records := trainer.GenerateRecordsFromEvents(events, trainer.Flags{Strategy: strategy.Name()}, strategy)
+	assert.Equal(t, "pinnacle", records[0].Match.BookmakerF)
+
+	output := filepath.Join(dir, "matches.csv")
+	assert.NoError(t, trainer.SaveToCSVWithOptions(records, output, trainer.CSVOptions{Match: trainer.HasMatchData(records)}))
+	loaded, err := trainer.ReadCSV(output)
+	assert.NoError(t, err)
+	assert.Equal(t, records[0].Match, loaded[0].Match)
+	assert.Equal(t, records[1].Match, loaded[1].Match)
+
+	// Legacy four-column files still load without metadata
+	legacy, err := common.ReadInputFile("xlDrop_001_smoke.input")
+	assert.NoError(t, err)
+	assert.True(t, legacy[0].Match.IsZero())
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/holygun/go-trainer/journal"
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestJournalRoundTrip checks that the hand-kept journal parses and is written back unchanged
+func TestJournalRoundTrip(t *testing.T) {
+	original, err := os.ReadFile("../new_line_0000")
+	assert.NoError(t, err)
+
+	book, err := journal.Parse(bytes.NewReader(original))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{366, 174, 144}, book.Counts)
+	assert.Equal(t, -7650000.0, book.Balance)
+	assert.Len(t, book.Sections, 2)
+
+	football := book.Sections[0]
+	assert.Equal(t, "Football", football.Sport)
+	assert.Equal(t, []int{300, 157, 141}, football.Counts)
+	assert.Equal(t, "Ирак - Индонезия", football.Match)
+	assert.Equal(t, 60000.0, football.Total)
+	assert.Equal(t, journal.Bet{Outcome: "X", Base: 10000, Stake: 6650, Bookmaker: "pm"}, football.Bets[1])
+	assert.Equal(t, "X", football.EventsFromOldest()[len(football.Events)-1])
+	assert.True(t, book.Sections[1].Hockey())
+
+	assert.Len(t, book.Results, 4)
+	assert.Equal(t, -575000.0, book.Results[2].Amount)
+
+	var written bytes.Buffer
+	assert.NoError(t, journal.Write(&written, book))
+	assert.Equal(t, strings.TrimRight(string(original), "\n"), strings.TrimRight(written.String(), "\n"))
+
+	_, err = journal.Parse(strings.NewReader("---Football---\nF:10000\nabc\n"))
+	assert.ErrorContains(t, err, "line 3: invalid stake")
+}
+
+// TestJournalCheckRealGame checks the journal stakes against the strategy
+// decision for the match row of real-games/xldrop.input and xldrop.actual
+func TestJournalCheckRealGame(t *testing.T) {
+	book, err := journal.ParseFile("../new_line_0000")
+	assert.NoError(t, err)
+	football, ok := book.Section("football")
+	assert.True(t, ok)
+	// The real game has the two newest journal events followed by the match
+	section := *football
+	section.Events = []string{"X", "X"}
+
+	strategy, err := trainer.GetStrategy("xlDrop")
+	assert.NoError(t, err)
+	cfg := trainer.DefaultConfig()
+	flags := trainer.Flags{Strategy: strategy.Name(), Config: &cfg}
+	events, err := trainer.ReadInputFile("../real-games/xldrop.input")
+	assert.NoError(t, err)
+	provider := trainer.NewInputOddsProvider(events, trainer.NewRandomOddsProvider(0, flags), flags)
+
+	odds, ok := section.MatchOdds(provider)
+	assert.True(t, ok)
+	assert.Equal(t, trainer.Odds{OddF: 1.88, OddX: 3.7, OddL: 4.3}, odds)
+	_, ok = section.MatchOdds(nil)
+	assert.False(t, ok)
+
+	decision, err := journal.NewSession(section, strategy, flags, provider).Advise(odds)
+	assert.NoError(t, err)
+	assert.Empty(t, journal.Check(section, decision))
+	// F:10000 is the base amount, not the loss the stake covers
+	assert.Equal(t, trainer.PerOutcome{F: 10000, X: 17850, L: 28300}, decision.Losses)
+
+	stored, err := trainer.ReadCSV("../real-games/xldrop.actual")
+	assert.NoError(t, err)
+	row := stored[2]
+	assert.Equal(t, 3, row.EventNumber)
+	assert.Equal(t, trainer.PerOutcome{F: row.BetF, X: row.BetX, L: row.BetL}, decision.Bets)
+
+	decision.Bets.L = 8650
+	assert.Equal(t, []journal.Mismatch{{Outcome: "L", Journal: 8600, Strategy: 8650}}, journal.Check(section, decision))
+
+	sheet := journal.Sheet(section, decision, "Next", cfg.DefaultBetF)
+	assert.Equal(t, journal.Bet{Outcome: "L", Base: 10000, Stake: 8650, Bookmaker: "bc"}, sheet.Bets[2])
+}
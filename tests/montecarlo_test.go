@@ -0,0 +1,33 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMonteCarloDeterministic checks that results depend only on the seed, not on the worker count
+func TestMonteCarloDeterministic(t *testing.T) {
+	for _, strategyName := range []string{"xlDrop", "xlWithSupport"} {
+		t.Run(strategyName, func(t *testing.T) {
+			strategy, err := trainer.GetStrategy(strategyName)
+			if err != nil {
+				t.Fatalf("Failed to get strategy: %v", err)
+			}
+
+			flags := trainer.Flags{Strategy: strategyName, Testing: true}
+			opts := trainer.MonteCarloOptions{Runs: 40, Events: 120, Seed: 7, Exposure: 200000}
+
+			opts.Workers = 1
+			single := trainer.RunMonteCarlo(strategy, flags, opts)
+			opts.Workers = 8
+			parallel := trainer.RunMonteCarlo(strategy, flags, opts)
+
+			assert.Equal(t, single.Runs, parallel.Runs)
+			assert.Equal(t, single.FinalTotal, parallel.FinalTotal)
+			assert.Equal(t, single.PatternEvents, parallel.PatternEvents)
+		})
+	}
+}
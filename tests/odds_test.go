@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// simulateToCSV runs the strategy like the simulate command and returns the saved CSV
+func simulateToCSV(t *testing.T, filename string, provider trainer.OddsProvider, flags trainer.Flags) ([]trainer.TrainerRecord, []byte) {
+	strategy, err := trainer.GetStrategy(flags.Strategy)
+	assert.NoError(t, err)
+	eventsFromOldest := trainer.ReverseSlice(trainer.ParseEvents("F/X/L/L/X/F/F/L/X/L/L/L/F/X/F"))
+	records := trainer.ReverseRecords(trainer.Simulate(eventsFromOldest, provider, flags, strategy))
+	meta := map[string]string{"strategy": strategy.Name()}
+	assert.NoError(t, trainer.SaveToCSVWithOptions(records, filename, trainer.CSVOptions{Meta: meta}))
+	data, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	return records, data
+}
+
+// TestSeededOddsReproducible checks that the same seed gives a byte-identical
+// CSV and that another seed gives other odds
+func TestSeededOddsReproducible(t *testing.T) {
+	dir := t.TempDir()
+	flags := trainer.Flags{Strategy: "xlDrop", Testing: true}
+
+	records, first := simulateToCSV(t, filepath.Join(dir, "first.csv"), trainer.NewRandomOddsProvider(42, flags), flags)
+	assert.Len(t, records, 15)
+	_, second := simulateToCSV(t, filepath.Join(dir, "second.csv"), trainer.NewRandomOddsProvider(42, flags), flags)
+	assert.Equal(t, string(first), string(second))
+
+	_, other := simulateToCSV(t, filepath.Join(dir, "other.csv"), trainer.NewRandomOddsProvider(43, flags), flags)
+	assert.NotEqual(t, string(first), string(other))
+}
+
+// TestReplayOddsProvider checks that replaying a saved CSV reproduces its odds
+// and records exactly, whatever the fallback seed
+func TestReplayOddsProvider(t *testing.T) {
+	dir := t.TempDir()
+	for _, strategyName := range []string{"xlDrop", "xlWithSupport"} {
+		flags := trainer.Flags{Strategy: strategyName, Testing: true}
+		saved := filepath.Join(dir, strategyName+".csv")
+		original, data := simulateToCSV(t, saved, trainer.NewRandomOddsProvider(7, flags), flags)
+
+		provider, err := trainer.NewReplayOddsProvider(saved, trainer.NewRandomOddsProvider(99, flags), flags)
+		assert.NoError(t, err)
+		for _, record := range original {
+			assert.Equal(t, trainer.Odds{OddF: record.OddF, OddX: record.OddX, OddL: record.OddL}, provider.Odds(record.EventNumber))
+		}
+
+		provider, err = trainer.NewReplayOddsProvider(saved, trainer.NewRandomOddsProvider(99, flags), flags)
+		assert.NoError(t, err)
+		replayed, replayedData := simulateToCSV(t, filepath.Join(dir, strategyName+"-replay.csv"), provider, flags)
+		assert.Equal(t, original, replayed)
+		assert.Equal(t, string(data), string(replayedData))
+	}
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseParam checks parsing of -param name=value
+func TestParseParam(t *testing.T) {
+	name, value, err := trainer.ParseParam(" ratio = 0.4 ")
+	assert.NoError(t, err)
+	assert.Equal(t, "ratio", name)
+	assert.Equal(t, 0.4, value)
+
+	for _, s := range []string{"ratio", "=0.4", "ratio=", "ratio=abc", ""} {
+		_, _, err := trainer.ParseParam(s)
+		assert.Error(t, err, s)
+	}
+}
+
+// TestValidateParams checks unknown names, ranges and integer types against
+// the strategy schema
+func TestValidateParams(t *testing.T) {
+	strategy, err := trainer.GetStrategy("xlDrop")
+	assert.NoError(t, err)
+
+	assert.NoError(t, trainer.ValidateParams(strategy, trainer.Params{"ratio": 0, "deferX": 3, "redWriteOff": 1}))
+	assert.ErrorContains(t, trainer.ValidateParams(strategy, trainer.Params{"ratoi": 0.4}), `no parameter "ratoi"`)
+	assert.ErrorContains(t, trainer.ValidateParams(strategy, trainer.Params{"ratio": 1.5}), "out of range")
+	assert.ErrorContains(t, trainer.ValidateParams(strategy, trainer.Params{"deferL": 0}), "out of range")
+	assert.ErrorContains(t, trainer.ValidateParams(strategy, trainer.Params{"deferX": 2.5}), "must be an integer")
+
+	// Config validation applies the same schema
+	cfg := trainer.DefaultConfig()
+	cfg.SetParam("xlDrop", "deferX", 2.5)
+	assert.Error(t, cfg.Validate())
+}
+
+// TestParamPrecedence checks that defaults are overridden by the config file
+// and the config by -param, and that SetParam leaves other copies untouched
+func TestParamPrecedence(t *testing.T) {
+	strategy, err := trainer.GetStrategy("xlDrop")
+	assert.NoError(t, err)
+
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(filename, []byte("strategies:\n  xlDrop: {ratio: 0.4, deferX: 7}\n"), 0644))
+	cfg, err := trainer.LoadConfig(filename)
+	assert.NoError(t, err)
+
+	// -param ratio=0.2 is applied over the loaded config
+	cli := cfg
+	cli.SetParam(strategy.Name(), "ratio", 0.2)
+	assert.NoError(t, cli.Validate())
+
+	params := cli.StrategyParams(strategy)
+	assert.Equal(t, 0.2, params["ratio"])
+	assert.Equal(t, 7.0, params["deferX"])
+	assert.Equal(t, 6.0, params["deferL"])
+	assert.Equal(t, 0.4, cfg.StrategyParams(strategy)["ratio"])
+	assert.Equal(t, 0.3, trainer.DefaultConfig().StrategyParams(strategy)["ratio"])
+
+	assert.Equal(t, "0.2", trainer.ParamsMeta(strategy, cli)["xlDrop.ratio"])
+}
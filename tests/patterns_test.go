@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSequenceConditions checks run, alternate, count and streak conditions on known event windows
+func TestSequenceConditions(t *testing.T) {
+	cases := []struct {
+		name      string
+		condition trainer.SequenceCondition
+		events    string
+		record    trainer.TrainerRecord
+		want      bool
+	}{
+		{"run of non-F", trainer.SequenceCondition{Kind: trainer.SequenceRun, Events: "XL", Length: 3}, "F/X/L/X", trainer.TrainerRecord{}, true},
+		{"run broken by F", trainer.SequenceCondition{Kind: trainer.SequenceRun, Events: "XL", Length: 3}, "X/F/L/X", trainer.TrainerRecord{}, false},
+		{"alternating X/L", trainer.SequenceCondition{Kind: trainer.SequenceAlternate, Events: "XL", Length: 4}, "F/X/L/X/L", trainer.TrainerRecord{}, true},
+		{"repeat breaks alternation", trainer.SequenceCondition{Kind: trainer.SequenceAlternate, Events: "XL", Length: 4}, "X/L/L/X", trainer.TrainerRecord{}, false},
+		{"only 2 of last 5 are L", trainer.SequenceCondition{Kind: trainer.SequenceCount, Events: "L", Length: 3, Window: 5}, "L/F/L/X/F/L", trainer.TrainerRecord{}, false},
+		{"window shorter than needed", trainer.SequenceCondition{Kind: trainer.SequenceCount, Events: "L", Length: 2, Window: 5}, "L/L", trainer.TrainerRecord{}, false},
+		{"uf streak", trainer.SequenceCondition{Kind: trainer.SequenceStreak, Counter: "uf", Length: 4}, "X", trainer.TrainerRecord{UF: 4}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := &trainer.SequenceRule{Name: "TEST", Level: trainer.SeverityLow, Conditions: []trainer.SequenceCondition{tc.condition}}
+			assert.NoError(t, rule.Validate())
+			ctx := trainer.PatternContext{Record: tc.record, RecentEvents: strings.Split(tc.events, "/")}
+			assert.Equal(t, tc.want, rule.Match(ctx))
+		})
+	}
+}
+
+// TestPatternRuleOverride checks that a config rule with a built-in ID replaces
+// the built-in rule instead of being added next to it
+func TestPatternRuleOverride(t *testing.T) {
+	cfg := trainer.DefaultConfig()
+	record := trainer.TrainerRecord{LossX: 150000}
+
+	// Built-in GREEN fires on one metric above small (100000)
+	matches := trainer.NewPatternDetector(cfg).AddEvent("F", 1, record)
+	assert.Contains(t, matches, trainer.PatternMatch{ID: "GREEN", Severity: trainer.SeverityLow})
+
+	cfg.Patterns.Rules = []trainer.ThresholdRule{{Name: "GREEN", Level: trainer.SeverityMedium,
+		Conditions: []trainer.ThresholdCondition{{Metrics: []string{"lossF"}, Above: "small", Count: 1}}}}
+	assert.NoError(t, cfg.Validate())
+
+	green := 0
+	for _, rule := range trainer.PatternRules(cfg) {
+		if rule.ID() == "GREEN" {
+			green++
+			assert.Equal(t, trainer.SeverityMedium, rule.Severity())
+		}
+	}
+	assert.Equal(t, 1, green)
+
+	matches = trainer.NewPatternDetector(cfg).AddEvent("F", 1, record)
+	assert.NotContains(t, matches, trainer.PatternMatch{ID: "GREEN", Severity: trainer.SeverityLow})
+	matches = trainer.NewPatternDetector(cfg).AddEvent("F", 1, trainer.TrainerRecord{LossF: 150000})
+	assert.Contains(t, matches, trainer.PatternMatch{ID: "GREEN", Severity: trainer.SeverityMedium})
+}
+
+// TestMultiplePatternMatches checks that every matching rule is recorded on the
+// event, most severe first, and the record keeps the highest severity
+func TestMultiplePatternMatches(t *testing.T) {
+	cfg := trainer.DefaultConfig()
+	// Three losses above big (200000) match RED, YELLOW and GREEN at once
+	record := trainer.TrainerRecord{LossF: 250000, LossX: 250000, LossL: 250000}
+	matches := trainer.NewPatternDetector(cfg).AddEvent("F", 1, record)
+	assert.Equal(t, []trainer.PatternMatch{
+		{ID: "RED", Severity: trainer.SeverityHigh},
+		{ID: "YELLOW", Severity: trainer.SeverityMedium},
+		{ID: "GREEN", Severity: trainer.SeverityLow},
+	}, matches)
+
+	// Low thresholds make patterns fire during a simulation
+	cfg.Patterns.SmallMult, cfg.Patterns.BigMult = 1, 2
+	strategy, err := trainer.GetStrategy("xlDrop")
+	assert.NoError(t, err)
+	flags := trainer.Flags{Strategy: strategy.Name(), Testing: true, Config: &cfg}
+	records := trainer.Simulate(trainer.ReverseSlice(trainer.ParseEvents("F/X/L/L/X/F/L/L/L/X")), trainer.NewRandomOddsProvider(3, flags), flags, strategy)
+
+	multiple := 0
+	for _, record := range records {
+		if len(record.Patterns) < 2 {
+			continue
+		}
+		multiple++
+		ids := make([]string, len(record.Patterns))
+		for i, match := range record.Patterns {
+			ids[i] = match.ID
+		}
+		assert.Equal(t, strings.Join(ids, "_"), record.Pattern)
+		assert.Equal(t, record.Patterns[0].Severity, record.Severity)
+		for _, id := range ids {
+			assert.True(t, record.HasPattern(id))
+		}
+	}
+	assert.Greater(t, multiple, 0)
+}
+
+// TestBuiltinSequenceRules checks that built-in sequence rules are off by
+// default, fire once enabled in patterns.builtin and can be overridden
+func TestBuiltinSequenceRules(t *testing.T) {
+	nofrun := func(cfg trainer.Config) []trainer.PatternMatch {
+		var found []trainer.PatternMatch
+		detector := trainer.NewPatternDetector(cfg)
+		for i, event := range strings.Split("X/L/X/L/L", "/") {
+			for _, match := range detector.AddEvent(event, i+1, trainer.TrainerRecord{}) {
+				if match.ID == "NOFRUN" {
+					found = append(found, match)
+				}
+			}
+		}
+		return found
+	}
+
+	cfg := trainer.DefaultConfig()
+	for _, rule := range trainer.PatternRules(cfg) {
+		assert.NotEqual(t, "NOFRUN", rule.ID())
+	}
+	assert.Empty(t, nofrun(cfg))
+
+	cfg.Patterns.Builtin = []string{"NOFRUN"}
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, []trainer.PatternMatch{{ID: "NOFRUN", Severity: trainer.SeverityLow}}, nofrun(cfg))
+
+	// A config sequence with the same ID replaces the enabled built-in rule
+	cfg.Patterns.Sequences = []trainer.SequenceRule{{Name: "NOFRUN", Level: trainer.SeverityHigh,
+		Conditions: []trainer.SequenceCondition{{Kind: trainer.SequenceRun, Events: "XL", Length: 3}}}}
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, []trainer.PatternMatch{
+		{ID: "NOFRUN", Severity: trainer.SeverityHigh},
+		{ID: "NOFRUN", Severity: trainer.SeverityHigh},
+		{ID: "NOFRUN", Severity: trainer.SeverityHigh},
+	}, nofrun(cfg))
+
+	cfg = trainer.DefaultConfig()
+	cfg.Patterns.Builtin = []string{"NOSUCH"}
+	assert.ErrorContains(t, cfg.Validate(), `patterns.builtin: unknown rule "NOSUCH"`)
+}
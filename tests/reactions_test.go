@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReactPolicies checks that the most severe pattern with a reaction wins and each policy adjusts the loss
+func TestReactPolicies(t *testing.T) {
+	strategy, err := trainer.GetStrategy("xlDrop")
+	assert.NoError(t, err)
+	previous := &trainer.TrainerRecord{Pattern: "RED_GREEN", BetF: 10000}
+
+	cases := []struct {
+		name      string
+		reactions map[string]trainer.Reaction
+		want      trainer.ReactionState
+	}{
+		{"default writeoff", nil,
+			trainer.ReactionState{RealLoss: 500000, Total: 500000, Applied: "RED:writeoff(0.5)"}},
+		{"severity key", map[string]trainer.Reaction{"high": {Policy: "cap", Max: 20}},
+			trainer.ReactionState{RealLoss: 200000, Total: 200000, Applied: "RED:cap(20)"}},
+		{"none stops lower severities", map[string]trainer.Reaction{"RED": {Policy: "none"}, "GREEN": {Policy: "move", To: "L"}},
+			trainer.ReactionState{RealLoss: 1000000, Total: 1000000}},
+		{"pause", map[string]trainer.Reaction{"RED": {Policy: "pause", Events: 3}},
+			trainer.ReactionState{RealLoss: 1000000, Total: 1000000, Pause: 3, Applied: "RED:pause(3)"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := trainer.DefaultConfig()
+			if tc.reactions != nil {
+				cfg.Reactions = map[string]map[string]trainer.Reaction{"xlDrop": tc.reactions}
+			}
+			assert.NoError(t, cfg.Validate())
+			assert.Equal(t, tc.want, trainer.React(strategy, previous, 1000000, 1000000, cfg))
+		})
+	}
+}
+
+// TestPauseKeepsLosses runs the pause policy through Simulate: the paused event
+// keeps the losses and streaks of the previous one, so the loss carried into
+// the next played event is the same as before the pause and nothing is written off
+func TestPauseKeepsLosses(t *testing.T) {
+	for _, name := range []string{"xlDrop", "xlWithSupport"} {
+		t.Run(name, func(t *testing.T) {
+			strategy, err := trainer.GetStrategy(name)
+			assert.NoError(t, err)
+			cfg := trainer.DefaultConfig()
+			// Every won L triggers a one-event pause
+			cfg.Patterns.Sequences = []trainer.SequenceRule{{Name: "LWIN", Level: trainer.SeverityLow,
+				Conditions: []trainer.SequenceCondition{{Kind: trainer.SequenceRun, Events: "L", Length: 1}}}}
+			cfg.Reactions = map[string]map[string]trainer.Reaction{name: {"LWIN": {Policy: "pause", Events: 1}}}
+			assert.NoError(t, cfg.Validate())
+
+			odds := make([]trainer.Odds, 7)
+			for i := range odds {
+				odds[i] = trainer.Odds{OddF: 2, OddX: 3.5, OddL: 4}
+			}
+			flags := trainer.Flags{Strategy: name, Testing: true, Trace: true, Config: &cfg}
+			sink := &recordingSink{}
+			flags.Bus = trainer.NewEventBus()
+			flags.Bus.Subscribe(sink, trainer.LevelInfo)
+			// Oldest first: X, F, X, F, L (pattern), X (paused, X wins), F
+			events := trainer.ReverseSlice(trainer.ParseEvents("F/X/L/F/X/F/X"))
+			records := trainer.Simulate(events, trainer.NewFixedOddsProvider(odds, nil, flags), flags, strategy)
+			assert.Len(t, records, 7)
+
+			before, paused, after := records[4], records[5], records[6]
+			assert.Equal(t, "L", before.Result)
+			assert.Equal(t, "LWIN:pause(1)", paused.Reaction)
+			assert.Equal(t, [3]float64{0, 0, 0}, [3]float64{paused.BetF, paused.BetX, paused.BetL})
+			assert.Equal(t, [3]float64{before.LossF, before.LossX, before.LossL}, [3]float64{paused.LossF, paused.LossX, paused.LossL})
+			assert.Equal(t, [3]float64{before.UF, before.UX, before.UL}, [3]float64{paused.UF, paused.UX, paused.UL})
+			assert.Equal(t, before.Total, paused.Total)
+
+			// The next played event carries the whole outstanding loss: an
+			// outcome with a zero streak (L, just won) counts as the base amount
+			want := -3 * cfg.DefaultBetF
+			for _, pair := range [][2]float64{{before.LossF, before.UF}, {before.LossX, before.UX}, {before.LossL, before.UL}} {
+				if pair[1] == 0 {
+					pair[0] = cfg.DefaultBetF
+				}
+				want += pair[0]
+			}
+			realLoss, ok := after.Trace.Get("realLoss")
+			assert.True(t, ok)
+			assert.Equal(t, strconv.FormatFloat(want, 'f', -1, 64), realLoss)
+			for _, event := range sink.events {
+				assert.NotEqual(t, "loss_written_off", event.Kind())
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRealGameMeta checks that .meta sidecars in YAML and JSON override the
+// strategy, sport, base bet and parameters derived from the file name
+func TestRealGameMeta(t *testing.T) {
+	dir := t.TempDir()
+
+	// Without a sidecar the file name decides
+	input := filepath.Join(dir, "xlDrop-hockey.input")
+	meta, err := trainer.LoadRealGameMeta(input)
+	assert.NoError(t, err)
+	settings, err := meta.Resolve("xlDrop", true, trainer.DefaultConfig())
+	assert.NoError(t, err)
+	assert.Equal(t, "xlDrop", settings.Strategy.Name())
+	assert.True(t, settings.Hockey)
+
+	input = filepath.Join(dir, "xldrop.input")
+	yamlMeta := "strategy: xlWithSupport\nsport: hockey\ndefault_bet: 5000\nparams:\n  ratio: 0.4\ntags: [TEST]\nnotes: cup games\n"
+	assert.NoError(t, os.WriteFile(trainer.RealGameMetaPath(input), []byte(yamlMeta), 0644))
+	meta, err = trainer.LoadRealGameMeta(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"TEST"}, meta.Tags)
+	assert.Equal(t, "cup games", meta.Notes)
+
+	settings, err = meta.Resolve("xldrop", false, trainer.DefaultConfig())
+	assert.NoError(t, err)
+	assert.Equal(t, "xlWithSupport", settings.Strategy.Name())
+	assert.True(t, settings.Hockey)
+	assert.Equal(t, 5000.0, settings.Config.DefaultBetF)
+	assert.Equal(t, 0.4, settings.Config.StrategyParams(settings.Strategy)["ratio"])
+
+	input = filepath.Join(dir, "xlDrop-hockey-TEST.input")
+	assert.NoError(t, os.WriteFile(trainer.RealGameMetaPath(input), []byte(`{"sport": "football"}`), 0644))
+	meta, err = trainer.LoadRealGameMeta(input)
+	assert.NoError(t, err)
+	settings, err = meta.Resolve("xlDrop", true, trainer.DefaultConfig())
+	assert.NoError(t, err)
+	assert.False(t, settings.Hockey)
+
+	// Unknown keys, sports and out-of-range parameters are reported
+	assert.NoError(t, os.WriteFile(trainer.RealGameMetaPath(input), []byte("sport: tennis\n"), 0644))
+	_, err = trainer.LoadRealGameMeta(input)
+	assert.ErrorContains(t, err, "sport")
+	assert.NoError(t, os.WriteFile(trainer.RealGameMetaPath(input), []byte("stratgy: xlDrop\n"), 0644))
+	_, err = trainer.LoadRealGameMeta(input)
+	assert.Error(t, err)
+	_, err = trainer.RealGameMeta{Params: trainer.Params{"ratio": 5}}.Resolve("xlDrop", false, trainer.DefaultConfig())
+	assert.Error(t, err)
+}
+
+// TestRealGameFingerprint checks that an .actual is regenerated exactly when
+// the input, the strategy or the effective settings change
+func TestRealGameFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "xlDrop.input")
+	actual := filepath.Join(dir, "xlDrop.actual")
+	assert.NoError(t, os.WriteFile(input, []byte("result,oddF,oddX,oddL\nX,2.0,3.5,4.0\nF,1.9,3.3,4.1\n"), 0644))
+
+	strategy, err := trainer.GetStrategy("xlDrop")
+	assert.NoError(t, err)
+	cfg := trainer.DefaultConfig()
+	flags := trainer.Flags{Strategy: strategy.Name(), Config: &cfg}
+	fingerprint, err := trainer.NewRealGameFingerprint(input, strategy, flags)
+	assert.NoError(t, err)
+	assert.Equal(t, trainer.StrategyVersion(strategy), fingerprint.StrategyVersion)
+
+	// Files written before fingerprints always need regeneration
+	events, err := trainer.ReadInputFile(input)
+	assert.NoError(t, err)
+	records := trainer.GenerateRecordsFromEvents(events, flags, strategy)
+	assert.NoError(t, trainer.SaveToCSVWithOptions(records, actual, trainer.CSVOptions{}))
+	stored, err := trainer.ReadRealGameFingerprint(actual)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, fingerprint.Changes(stored))
+
+	assert.NoError(t, trainer.SaveToCSVWithOptions(records, actual, trainer.CSVOptions{Meta: fingerprint.Meta()}))
+	stored, err = trainer.ReadRealGameFingerprint(actual)
+	assert.NoError(t, err)
+	assert.Empty(t, fingerprint.Changes(stored))
+
+	// Same line count, different odd
+	assert.NoError(t, os.WriteFile(input, []byte("result,oddF,oddX,oddL\nX,2.0,3.5,4.2\nF,1.9,3.3,4.1\n"), 0644))
+	changed, err := trainer.NewRealGameFingerprint(input, strategy, flags)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"изменен .input"}, changed.Changes(stored))
+
+	// Different parameters and sport
+	assert.NoError(t, os.WriteFile(input, []byte("result,oddF,oddX,oddL\nX,2.0,3.5,4.0\nF,1.9,3.3,4.1\n"), 0644))
+	tuned := cfg
+	tuned.SetParam(strategy.Name(), "ratio", 0.4)
+	changed, err = trainer.NewRealGameFingerprint(input, strategy, trainer.Flags{Config: &tuned})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"изменены настройки расчета"}, changed.Changes(stored))
+	changed, err = trainer.NewRealGameFingerprint(input, strategy, trainer.Flags{Config: &cfg, Hockey: true})
+	assert.NoError(t, err)
+	assert.Len(t, changed.Changes(stored), 1)
+
+	other, err := trainer.GetStrategy("xlWithSupport")
+	assert.NoError(t, err)
+	changed, err = trainer.NewRealGameFingerprint(input, other, flags)
+	assert.NoError(t, err)
+	assert.Contains(t, changed.Changes(stored), "стратегия xlDrop -> xlWithSupport")
+
+	// Settings that do not affect this file keep the fingerprint
+	unrelated := cfg
+	unrelated.Tags = []string{"cup"}
+	unrelated.OddsRanges.OddF.Max = 2.5
+	unrelated.SetParam(other.Name(), "writeOff", 0.2)
+	unrelated.Reactions = map[string]map[string]trainer.Reaction{other.Name(): {"RED": {Policy: "none"}}}
+	unrelated.Bookmakers = trainer.BookmakerConfig{Preferred: "bc"}
+	changed, err = trainer.NewRealGameFingerprint(input, strategy, trainer.Flags{Config: &unrelated})
+	assert.NoError(t, err)
+	assert.Empty(t, changed.Changes(stored))
+
+	// This strategy's reactions do
+	reacted := cfg
+	reacted.Reactions = map[string]map[string]trainer.Reaction{strategy.Name(): {"RED": {Policy: "none"}}}
+	changed, err = trainer.NewRealGameFingerprint(input, strategy, trainer.Flags{Config: &reacted})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"изменены настройки расчета"}, changed.Changes(stored))
+
+	// Bookmaker settings count only for inputs with per-bookmaker columns
+	lines := filepath.Join(dir, "xlDrop-lines.input")
+	assert.NoError(t, os.WriteFile(lines, []byte("result,oddF@bc,oddX@bc,oddL@bc,oddF@pm,oddX@pm,oddL@pm\nX,2.0,3.5,4.0,2.1,3.4,4.1\n"), 0644))
+	single, err := trainer.NewRealGameFingerprint(lines, strategy, flags)
+	assert.NoError(t, err)
+	preferred, err := trainer.NewRealGameFingerprint(lines, strategy, trainer.Flags{Config: &unrelated})
+	assert.NoError(t, err)
+	assert.NotEqual(t, single.ConfigHash, preferred.ConfigHash)
+}
+
+// strategyGoldens versions of the built-in strategies and SHA-256 of their
+// golden records (see strategyGoldenHash). A new hash needs a new Version(),
+// otherwise trainer real keeps .actual files computed the old way.
+var strategyGoldens = map[string]struct{ version, hash string }{
+	"xlDrop":        {"1", "a2bcb469a8c5f55b"},
+	"xlWithSupport": {"1", "106773adce64c86c"},
+}
+
+// strategyGoldenHash simulates a fixed event history with seeded odds for
+// football and hockey, in real and testing settlement, and hashes the numeric
+// fields of the records, so CSV format changes do not affect it
+func strategyGoldenHash(t *testing.T, strategy trainer.Strategy) string {
+	history := "F/X/L/L/X/F/F/L/X/L/L/L/F/X/F/X/X/L/X/L/F/F/F/X/L/L/X/X/X/L/F/L/X/L/L/F/X/F/F/L/X/X/L/L/L/X/F/X/L/F/N"
+	hash := sha256.New()
+	for _, hockey := range []bool{false, true} {
+		for _, real := range []bool{false, true} {
+			cfg := trainer.DefaultConfig()
+			flags := trainer.Flags{Strategy: strategy.Name(), Hockey: hockey, Real: real, Testing: !real, Config: &cfg}
+			records := trainer.Simulate(trainer.ReverseSlice(trainer.ParseEvents(history)), trainer.NewRandomOddsProvider(7, flags), flags, strategy)
+			for _, r := range records {
+				fmt.Fprintf(hash, "%d %s %g %g %g %g %g %g %g %g %g %g %g %g %g\n", r.EventNumber, r.Result,
+					r.OddF, r.OddX, r.OddL, r.BetF, r.BetX, r.BetL, r.LossF, r.LossX, r.LossL, r.Total, r.UF, r.UX, r.UL)
+			}
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// TestStrategyVersionGolden fails when the output of a strategy changes while
+// its Version() stays the same
+func TestStrategyVersionGolden(t *testing.T) {
+	for _, name := range trainer.StrategyNames() {
+		strategy, err := trainer.GetStrategy(name)
+		assert.NoError(t, err)
+		golden, ok := strategyGoldens[name]
+		if !assert.True(t, ok, "add %s to strategyGoldens", name) {
+			continue
+		}
+		version, hash := trainer.StrategyVersion(strategy), strategyGoldenHash(t, strategy)
+		if version == golden.version {
+			assert.Equal(t, golden.hash, hash, "%s output changed: bump its Version() and record the new version and hash", name)
+		} else {
+			assert.Equal(t, golden.version, version, "%s version changed: record version %s and hash %s in strategyGoldens", name, version, hash)
+		}
+	}
+}
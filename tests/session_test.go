@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/holygun/go-trainer/common"
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sessionEvents is a played history with different odds for every event
+var sessionEvents = []common.Event{
+	{Result: "X", OddF: 1.9, OddX: 3.6, OddL: 4.2},
+	{Result: "L", OddF: 2.1, OddX: 3.4, OddL: 3.8},
+	{Result: "F", OddF: 1.75, OddX: 3.9, OddL: 4.6},
+	{Result: "L", OddF: 2.05, OddX: 3.5, OddL: 3.9},
+	{Result: "L", OddF: 2.2, OddX: 3.3, OddL: 3.6},
+	{Result: "X", OddF: 1.85, OddX: 3.7, OddL: 4.4},
+	{Result: "F", OddF: 1.95, OddX: 3.6, OddL: 4.1},
+	{Result: "X", OddF: 2.0, OddX: 3.5, OddL: 4.0},
+}
+
+// TestSessionMatchesSimulation checks that advising and settling the events one
+// by one, saving and loading the session between steps, gives the same records
+// as GenerateRecordsFromEvents over the whole history
+func TestSessionMatchesSimulation(t *testing.T) {
+	for _, name := range []string{"xlDrop", "xlWithSupport"} {
+		t.Run(name, func(t *testing.T) {
+			strategy, err := trainer.GetStrategy(name)
+			assert.NoError(t, err)
+			cfg := trainer.DefaultConfig()
+			cfg.Bankroll.Capital = 500000
+			flags := trainer.Flags{Strategy: strategy.Name(), Config: &cfg}
+
+			expected := trainer.GenerateRecordsFromEvents(sessionEvents, flags, strategy)
+			assert.Len(t, expected, len(sessionEvents))
+
+			file := filepath.Join(t.TempDir(), "session.json")
+			assert.NoError(t, trainer.NewSession(strategy, flags, nil).Save(file))
+			for i, event := range sessionEvents {
+				session, err := trainer.LoadSession(file)
+				assert.NoError(t, err)
+				decision, err := session.Advise(trainer.Odds{OddF: event.OddF, OddX: event.OddX, OddL: event.OddL})
+				assert.NoError(t, err)
+				assert.Equal(t, trainer.PerOutcome{F: expected[i].BetF, X: expected[i].BetX, L: expected[i].BetL}, decision.Bets, "event %d", i+1)
+				assert.NoError(t, session.Save(file))
+
+				session, err = trainer.LoadSession(file)
+				assert.NoError(t, err)
+				record, err := session.Settle(event.Result)
+				assert.NoError(t, err)
+				assert.Equal(t, expected[i], record, "event %d", i+1)
+				assert.NoError(t, session.Save(file))
+			}
+		})
+	}
+}
+
+// TestSessionSettleWithoutAdvice checks that a result without advised bets is rejected
+func TestSessionSettleWithoutAdvice(t *testing.T) {
+	strategy, err := trainer.GetStrategy("xlDrop")
+	assert.NoError(t, err)
+	cfg := trainer.DefaultConfig()
+	session := trainer.NewSession(strategy, trainer.Flags{Strategy: strategy.Name(), Config: &cfg}, sessionEvents)
+
+	_, err = session.Settle("F")
+	assert.ErrorContains(t, err, "no pending advice")
+	assert.Equal(t, len(sessionEvents), session.Last.EventNumber)
+
+	_, err = session.Advise(trainer.Odds{OddF: 2, OddX: 3.5, OddL: 4})
+	assert.NoError(t, err)
+	_, err = session.Settle("F")
+	assert.NoError(t, err)
+	_, err = session.Settle("X")
+	assert.ErrorContains(t, err, "no pending advice")
+}
@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSettleUnplayedEvent checks that an unplayed event (result N) is settled
+// per strategy exactly as before the shared Settle engine: xlDrop marks losses
+// and streaks with -1 in real mode and keeps them otherwise, xlWithSupport
+// keeps the decision losses and adds the base amount to the total
+func TestSettleUnplayedEvent(t *testing.T) {
+	events := []string{"X", "L", "L", "F", "N"}
+	odds := []struct{ OddF, OddX, OddL float64 }{
+		{1.9, 3.5, 4.0}, {2.0, 3.4, 4.1}, {1.85, 3.6, 4.2}, {1.95, 3.3, 4.3}, {1.88, 3.7, 4.3},
+	}
+	// Values produced by the per-strategy Calculate before the refactoring
+	tests := []struct {
+		strategy string
+		flags    trainer.Flags
+		expected trainer.TrainerRecord
+	}{
+		{"xlDrop", trainer.Flags{Real: true}, trainer.TrainerRecord{BetF: 11400, BetX: 8150, BetL: 11500,
+			LossF: -1, LossX: -1, LossL: -1, Total: 40000, UF: -1, UX: -1, UL: -1}},
+		{"xlDrop", trainer.Flags{Testing: true}, trainer.TrainerRecord{BetF: 11400, BetX: 8150, BetL: 11500,
+			LossF: 10000, LossX: 22000, LossL: 37950, Total: 40000, UF: 0, UX: 3, UL: 1}},
+		{"xlDrop", trainer.Flags{}, trainer.TrainerRecord{BetF: 11400, BetX: 8150, BetL: 11500,
+			LossF: 10000, LossX: 22000, LossL: 37950, Total: 40000, UF: 0, UX: 3, UL: 1}},
+		{"xlWithSupport", trainer.Flags{Real: true}, trainer.TrainerRecord{BetF: 25400, BetX: 9100, BetL: 13250,
+			LossF: 22350, LossX: 24500, LossL: 43700, Total: 50000, UF: 0, UX: 3, UL: 1}},
+		{"xlWithSupport", trainer.Flags{Testing: true}, trainer.TrainerRecord{BetF: 25400, BetX: 9100, BetL: 13250,
+			LossF: 22350, LossX: 24500, LossL: 43700, Total: 50000, UF: 0, UX: 3, UL: 1}},
+	}
+	for _, tt := range tests {
+		strategy, err := trainer.GetStrategy(tt.strategy)
+		assert.NoError(t, err)
+		tt.flags.Strategy = tt.strategy
+		records := trainer.GenerateRecordsWithOdds(events, odds, tt.flags, strategy)
+		last := records[len(records)-1]
+		assert.Equal(t, "N", last.Result)
+		got := trainer.TrainerRecord{BetF: last.BetF, BetX: last.BetX, BetL: last.BetL,
+			LossF: last.LossF, LossX: last.LossX, LossL: last.LossL, Total: last.Total,
+			UF: last.UF, UX: last.UX, UL: last.UL}
+		assert.Equal(t, tt.expected, got, "%s real=%v testing=%v", tt.strategy, tt.flags.Real, tt.flags.Testing)
+	}
+}
@@ -0,0 +1,210 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sweepSetup returns xlDrop, test flags and n random sequences of 60 events
+func sweepSetup(t *testing.T, n int) (trainer.Strategy, trainer.Flags, []trainer.SweepSequence) {
+	strategy, err := trainer.GetStrategy("xlDrop")
+	assert.NoError(t, err)
+	flags := trainer.Flags{Strategy: strategy.Name(), Testing: true}
+	return strategy, flags, trainer.RandomSweepSequences(flags, n, 60, 11)
+}
+
+// dimension parses a range and fails the test on error
+func dimension(t *testing.T, s string) trainer.SweepDimension {
+	dim, err := trainer.ParseSweepDimension(s)
+	assert.NoError(t, err)
+	return dim
+}
+
+// candidateValues lists the parameter values of the candidates in rank order
+func candidateValues(report trainer.SweepReport) [][]float64 {
+	values := make([][]float64, len(report.Candidates))
+	for i, candidate := range report.Candidates {
+		values[i] = candidate.Values
+	}
+	return values
+}
+
+// TestParseSweep checks parsing of ranges, objectives and constraints
+func TestParseSweep(t *testing.T) {
+	assert.Equal(t, []float64{0.1, 0.2, 0.3}, dimension(t, "ratio=0.1:0.3:0.1").Values)
+	assert.Equal(t, []float64{3, 5, 8}, dimension(t, "deferX=3,5,8").Values)
+	for _, s := range []string{"ratio", "ratio=", "=0.1", "ratio=0.3:0.1:0.1", "ratio=0.1:0.3:0", "ratio=a:1:0.1", "ratio=0.1,x"} {
+		_, err := trainer.ParseSweepDimension(s)
+		assert.Error(t, err, s)
+	}
+
+	objective, err := trainer.ParseSweepObjective("max:total")
+	assert.NoError(t, err)
+	assert.Equal(t, trainer.SweepObjective{Metric: "total", Maximize: true}, objective)
+	for _, s := range []string{"total", "avg:total", "max:profit", "min:"} {
+		_, err := trainer.ParseSweepObjective(s)
+		assert.Error(t, err, s)
+	}
+
+	constraint, err := trainer.ParseSweepConstraint("ruined<=0.05")
+	assert.NoError(t, err)
+	assert.Equal(t, trainer.SweepConstraint{Metric: "ruined", Op: "<=", Value: 0.05}, constraint)
+	assert.True(t, constraint.Holds(trainer.SweepMetrics{Ruined: 0.05}))
+	assert.False(t, constraint.Holds(trainer.SweepMetrics{Ruined: 0.1}))
+	for _, s := range []string{"total>1000", "profit>=1", "total>=abc", "total"} {
+		_, err := trainer.ParseSweepConstraint(s)
+		assert.Error(t, err, s)
+	}
+}
+
+// TestSweepGridOrder checks that the grid has every combination and, for equal
+// metrics, keeps the enumeration order with the last dimension varying fastest
+func TestSweepGridOrder(t *testing.T) {
+	strategy, flags, sequences := sweepSetup(t, 2)
+	// Streaks never reach these thresholds in 60 events, so all metrics are equal
+	report, err := trainer.RunSweep(strategy, flags, trainer.SweepOptions{
+		Dimensions: []trainer.SweepDimension{dimension(t, "deferX=100,200"), dimension(t, "deferL=100,200,300")},
+		Sequences:  sequences,
+		Objective:  trainer.SweepObjective{Metric: "total", Maximize: true},
+		Mode:       trainer.SweepGrid,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float64{
+		{100, 100}, {100, 200}, {100, 300},
+		{200, 100}, {200, 200}, {200, 300},
+	}, candidateValues(report))
+}
+
+// TestSweepDeterministic checks that random mode depends only on the seed and
+// the ranking does not depend on the worker count
+func TestSweepDeterministic(t *testing.T) {
+	strategy, flags, sequences := sweepSetup(t, 4)
+	opts := trainer.SweepOptions{
+		Dimensions: []trainer.SweepDimension{dimension(t, "ratio=0.1:0.9:0.1"), dimension(t, "deferX=3,4,5,6")},
+		Sequences:  sequences,
+		Objective:  trainer.SweepObjective{Metric: "peak_stake"},
+		Mode:       trainer.SweepRandom,
+		Samples:    12,
+		Seed:       5,
+		Workers:    1,
+	}
+	first, err := trainer.RunSweep(strategy, flags, opts)
+	assert.NoError(t, err)
+	opts.Workers = 8
+	second, err := trainer.RunSweep(strategy, flags, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, first.Candidates, second.Candidates)
+	assert.LessOrEqual(t, len(first.Candidates), 12)
+
+	opts.Seed = 6
+	other, err := trainer.RunSweep(strategy, flags, opts)
+	assert.NoError(t, err)
+	assert.NotEqual(t, candidateValues(first), candidateValues(other))
+
+	opts.Mode, opts.Workers = trainer.SweepGrid, 1
+	single, err := trainer.RunSweep(strategy, flags, opts)
+	assert.NoError(t, err)
+	opts.Workers = 8
+	parallel, err := trainer.RunSweep(strategy, flags, opts)
+	assert.NoError(t, err)
+	assert.Len(t, single.Candidates, 9*4)
+	assert.Equal(t, single.Candidates, parallel.Candidates)
+}
+
+// TestSweepCoordinateDescent checks that coordinate descent reaches the grid
+// optimum while evaluating fewer combinations
+func TestSweepCoordinateDescent(t *testing.T) {
+	strategy, flags, sequences := sweepSetup(t, 3)
+	opts := trainer.SweepOptions{
+		Dimensions: []trainer.SweepDimension{dimension(t, "ratio=0.1:0.9:0.2"), dimension(t, "redWriteOff=0,0.5,1")},
+		Sequences:  sequences,
+		Objective:  trainer.SweepObjective{Metric: "peak_stake"},
+		Mode:       trainer.SweepGrid,
+	}
+	grid, err := trainer.RunSweep(strategy, flags, opts)
+	assert.NoError(t, err)
+	opts.Mode = trainer.SweepCoord
+	coord, err := trainer.RunSweep(strategy, flags, opts)
+	assert.NoError(t, err)
+
+	assert.Equal(t, grid.Candidates[0].Metrics.PeakStake, coord.Candidates[0].Metrics.PeakStake)
+	assert.Less(t, len(coord.Candidates), len(grid.Candidates))
+}
+
+// TestSweepErrors checks option errors and that an invalid combination is
+// reported in the candidate instead of aborting the sweep
+func TestSweepErrors(t *testing.T) {
+	strategy, flags, sequences := sweepSetup(t, 1)
+	objective := trainer.SweepObjective{Metric: "total", Maximize: true}
+	ratio := dimension(t, "ratio=0.3")
+
+	_, err := trainer.RunSweep(strategy, flags, trainer.SweepOptions{Dimensions: []trainer.SweepDimension{dimension(t, "ratoi=0.3")}, Sequences: sequences, Objective: objective})
+	assert.ErrorContains(t, err, `no parameter "ratoi"`)
+	_, err = trainer.RunSweep(strategy, flags, trainer.SweepOptions{Dimensions: []trainer.SweepDimension{{Name: "ratio"}}, Sequences: sequences, Objective: objective})
+	assert.ErrorContains(t, err, "empty range")
+	_, err = trainer.RunSweep(strategy, flags, trainer.SweepOptions{Sequences: sequences, Objective: objective})
+	assert.Error(t, err)
+	_, err = trainer.RunSweep(strategy, flags, trainer.SweepOptions{Dimensions: []trainer.SweepDimension{ratio}, Objective: objective})
+	assert.Error(t, err)
+	_, err = trainer.RunSweep(strategy, flags, trainer.SweepOptions{Dimensions: []trainer.SweepDimension{ratio}, Sequences: sequences, Objective: objective, Mode: "annealing"})
+	assert.ErrorContains(t, err, "unknown sweep mode")
+
+	// ratio=2 is out of the parameter range, round_up=0 fails config validation
+	report, err := trainer.RunSweep(strategy, flags, trainer.SweepOptions{
+		Dimensions: []trainer.SweepDimension{dimension(t, "ratio=0.3,2"), dimension(t, "round_up=0,50")},
+		Sequences:  sequences,
+		Objective:  objective,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, report.Candidates, 4)
+	assert.Equal(t, []float64{0.3, 50}, report.Candidates[0].Values)
+	assert.Empty(t, report.Candidates[0].Err)
+	for _, candidate := range report.Candidates[1:] {
+		assert.NotEmpty(t, candidate.Err, "%v", candidate.Values)
+		assert.False(t, candidate.Feasible)
+	}
+}
+
+// TestInputSweepSequencesSport checks that real-games sequences take the sport
+// from .meta files and tags the same way as a recompute of the file
+func TestInputSweepSequencesSport(t *testing.T) {
+	dir := t.TempDir()
+	input, err := os.ReadFile("../real-games/xldrop.input")
+	assert.NoError(t, err)
+	files := map[string]string{
+		"xlDrop":          "",
+		"xlDrop_meta":     "sport: hockey\n",
+		"xlDrop_tags":     "tags: [hockey]\n",
+		"xlDrop-hockey":   "",
+		"xlDrop-hockey_f": "sport: football\n",
+	}
+	for name, meta := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name+".input"), input, 0644))
+		if meta != "" {
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, name+".meta"), []byte(meta), 0644))
+		}
+	}
+
+	sequences, err := trainer.InputSweepSequences(dir)
+	assert.NoError(t, err)
+	hockey := map[string]bool{}
+	for _, sequence := range sequences {
+		hockey[sequence.Name] = sequence.Hockey
+	}
+	assert.Equal(t, map[string]bool{
+		"xlDrop":          false,
+		"xlDrop_meta":     true,
+		"xlDrop_tags":     true,
+		"xlDrop-hockey":   true,
+		"xlDrop-hockey_f": false,
+	}, hockey)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "xlDrop.meta"), []byte("sport: curling\n"), 0644))
+	_, err = trainer.InputSweepSequences(dir)
+	assert.ErrorContains(t, err, "sport")
+}
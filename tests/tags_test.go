@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRealGameTags checks tag parsing from file names, the registry with
+// config-defined tags and include/exclude filtering
+func TestRealGameTags(t *testing.T) {
+	strategy, tags := trainer.ParseRealGameName("real-games/xlDrop-hockey-test.input")
+	assert.Equal(t, "xlDrop", strategy)
+	assert.Equal(t, []string{"hockey", "test"}, tags)
+
+	strategy, tags = trainer.ParseRealGameName("xlDrop.input")
+	assert.Equal(t, "xlDrop", strategy)
+	assert.Empty(t, tags)
+
+	registry, err := trainer.NewTagRegistry("cup")
+	assert.NoError(t, err)
+	resolved, err := registry.Resolve([]string{"hockey", "test", "CUP"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hockey", "TEST", "cup"}, resolved)
+
+	_, err = registry.Resolve([]string{"TEST", "weird"})
+	assert.ErrorContains(t, err, "weird")
+
+	_, err = trainer.NewTagRegistry("bad-tag")
+	assert.Error(t, err)
+
+	filter := trainer.TagFilter{Include: []string{"TEST", "PROD"}, Exclude: []string{"hockey"}}
+	ok, _ := filter.Match([]string{"TEST"})
+	assert.True(t, ok)
+	ok, reason := filter.Match([]string{"TEST", "hockey"})
+	assert.False(t, ok)
+	assert.Contains(t, reason, "hockey")
+	ok, _ = filter.Match(nil)
+	assert.False(t, ok)
+
+	// An empty filter lets every file through
+	ok, _ = trainer.TagFilter{}.Match([]string{"STAGING"})
+	assert.True(t, ok)
+}
@@ -0,0 +1,35 @@
+package tests
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/holygun/go-trainer/trainer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWideCSVTraceRoundTrip checks that trace values survive the wide CSV and feed explain
+func TestWideCSVTraceRoundTrip(t *testing.T) {
+	strategy, err := trainer.GetStrategy("xlDrop")
+	assert.NoError(t, err)
+	events := trainer.ReverseSlice(trainer.ParseEvents("F/X/L/X/L/X/L/X/L/X/L/X"))
+	flags := trainer.Flags{Strategy: strategy.Name(), Seed: 7, Trace: true}
+	records := trainer.ReverseRecords(trainer.GenerateRecords(events, flags, strategy))
+
+	filename := filepath.Join(t.TempDir(), "wide.csv")
+	assert.NoError(t, trainer.SaveToCSVWithOptions(records, filename, trainer.CSVOptions{Trace: true}))
+	loaded, err := trainer.ReadCSV(filename)
+	assert.NoError(t, err)
+	for i := range records {
+		assert.Equal(t, records[i].Trace, loaded[i].Trace, "event %d", records[i].EventNumber)
+	}
+
+	lines, err := trainer.Explain(loaded, 8, strategy)
+	assert.NoError(t, err)
+	assert.Contains(t, strings.Join(lines, "\n"), "(realLoss)")
+
+	_, err = trainer.Explain(loaded, 100, strategy)
+	assert.Error(t, err)
+}
@@ -0,0 +1,117 @@
+package trainer
+
+import (
+	"fmt"
+	"math"
+)
+
+// Реакции на превышение лимита ставок
+const (
+	OnCapStop     = "stop"     // Остановить расчет
+	OnCapClamp    = "clamp"    // Урезать ставку, непокрытый убыток перенести дальше
+	OnCapWriteOff = "writeoff" // Урезать ставку, непокрытый убыток списать из итога
+)
+
+// Отметки TrainerRecord.Limit
+const (
+	LimitRuin = "ruin" // Банка не хватает на ставки, расчет остановлен
+)
+
+// BankrollConfig банк и лимиты ставок. Нулевые значения означают отсутствие ограничения.
+type BankrollConfig struct {
+	Capital  float64    `json:"capital" yaml:"capital"`     // Начальный банк
+	MaxStake float64    `json:"max_stake" yaml:"max_stake"` // Лимит суммы betF+betX+betL на событие
+	MaxBet   PerOutcome `json:"max_bet" yaml:"max_bet"`     // Лимиты ставки на исход (как у букмекера)
+	OnCap    string     `json:"on_cap" yaml:"on_cap"`       // OnCapStop, OnCapClamp или OnCapWriteOff
+}
+
+// Enabled сообщает, задан ли банк или хотя бы один лимит
+func (b BankrollConfig) Enabled() bool {
+	return b.Capital > 0 || b.MaxStake > 0 || b.MaxBet.F > 0 || b.MaxBet.X > 0 || b.MaxBet.L > 0
+}
+
+// Validate проверяет настройки банка
+func (b BankrollConfig) Validate() error {
+	if b.Capital < 0 || b.MaxStake < 0 || b.MaxBet.F < 0 || b.MaxBet.X < 0 || b.MaxBet.L < 0 {
+		return fmt.Errorf("bankroll: capital and limits must not be negative")
+	}
+	switch b.OnCap {
+	case OnCapStop, OnCapClamp, OnCapWriteOff:
+		return nil
+	}
+	return fmt.Errorf("bankroll.on_cap: expected %s, %s or %s, got %q", OnCapStop, OnCapClamp, OnCapWriteOff, b.OnCap)
+}
+
+// roundDown округляет значение вниз до кратного c.RoundUp
+func (c Config) roundDown(value float64) float64 {
+	return math.Floor(value/c.RoundUp) * c.RoundUp
+}
+
+// applyLimits приводит решение стратегии к лимитам ставок (реакция OnCap)
+// и проверяет, хватает ли банка на ставки. bankroll - банк перед событием
+// (учитывается, если задан Capital).
+func applyLimits(decision BetDecision, odds Odds, cfg Config, bankroll float64) BetDecision {
+	limits := cfg.Bankroll
+	if !limits.Enabled() {
+		return decision
+	}
+
+	capped := decision.Bets
+	for _, outcome := range Outcomes {
+		if maxBet := limits.MaxBet.Get(outcome); maxBet > 0 && capped.Get(outcome) > maxBet {
+			capped.Set(outcome, maxBet)
+		}
+	}
+
+	if limits.MaxStake > 0 && capped.Sum() > limits.MaxStake {
+		factor := limits.MaxStake / capped.Sum()
+		for _, outcome := range Outcomes {
+			capped.Set(outcome, cfg.roundDown(capped.Get(outcome)*factor))
+		}
+	}
+
+	// Ставки, на которые не хватает банка, сделать нельзя
+	if limits.Capital > 0 && capped.Sum() > bankroll {
+		decision.Limit = LimitRuin
+		return decision
+	}
+
+	if capped == decision.Bets {
+		return decision
+	}
+
+	if limits.OnCap == OnCapStop {
+		decision.Limit = OnCapStop
+		return decision
+	}
+
+	odd := PerOutcome{F: odds.OddF, X: odds.OddX, L: odds.OddL}
+	for _, outcome := range Outcomes {
+		bet := capped.Get(outcome)
+		if bet >= decision.Bets.Get(outcome) {
+			continue
+		}
+
+		// Урезанная ставка при выигрыше покрывает только часть убытка
+		planned := decision.Losses.Get(outcome) - decision.Residual.Get(outcome)
+		uncovered := math.Max(0, planned-bet*(odd.Get(outcome)-1))
+		if limits.OnCap == OnCapClamp {
+			decision.Residual.Set(outcome, decision.Residual.Get(outcome)+uncovered)
+		} else {
+			decision.Losses.Set(outcome, decision.Losses.Get(outcome)-uncovered)
+			decision.Total -= uncovered
+		}
+		decision.Coverage.Set(outcome, math.Min(decision.Coverage.Get(outcome), bet))
+	}
+	decision.Bets = capped
+	decision.Limit = limits.OnCap
+
+	return decision
+}
+
+// settleBankroll возвращает банк после события: ставки списываются,
+// выигравшая ставка возвращается с выигрышем
+func settleBankroll(bankroll float64, bets PerOutcome, odds Odds, result string) float64 {
+	odd := PerOutcome{F: odds.OddF, X: odds.OddX, L: odds.OddL}
+	return bankroll - bets.Sum() + bets.Get(result)*odd.Get(result)
+}
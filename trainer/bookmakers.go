@@ -0,0 +1,112 @@
+package trainer
+
+import (
+	"fmt"
+
+	"github.com/holygun/go-trainer/common"
+)
+
+// BookmakerConfig выбор букмекера для ставок, когда у события есть
+// коэффициенты нескольких букмекеров (колонки oddF@name в .input)
+type BookmakerConfig struct {
+	Preferred string  `json:"preferred,omitempty" yaml:"preferred,omitempty"` // Предпочитаемый букмекер
+	Tolerance float64 `json:"tolerance,omitempty" yaml:"tolerance,omitempty"` // Насколько (доля) коэффициент предпочитаемого может уступать лучшему
+	Baseline  string  `json:"baseline,omitempty" yaml:"baseline,omitempty"`   // Букмекер для оценки выбора линии (по умолчанию Preferred)
+}
+
+// Validate проверяет настройки выбора букмекера
+func (b BookmakerConfig) Validate() error {
+	if b.Tolerance < 0 || b.Tolerance >= 1 {
+		return fmt.Errorf("bookmakers.tolerance: expected a fraction in [0, 1), got %v", b.Tolerance)
+	}
+	if b.Tolerance > 0 && b.Preferred == "" {
+		return fmt.Errorf("bookmakers.tolerance requires bookmakers.preferred")
+	}
+	return nil
+}
+
+// baseline возвращает букмекера для сравнения
+func (b BookmakerConfig) baseline() string {
+	if b.Baseline != "" {
+		return b.Baseline
+	}
+	return b.Preferred
+}
+
+// LineChoice выбранные для ставок коэффициенты и букмекеры по исходам
+type LineChoice struct {
+	Odds       Odds
+	Bookmakers map[string]string // Исход -> букмекер
+}
+
+// SelectLine выбирает для каждого исхода букмекера с лучшим коэффициентом.
+// Предпочитаемый букмекер выбирается, если его коэффициент уступает лучшему
+// не больше чем на Tolerance (доля лучшего коэффициента). Коэффициенты не
+// выше 1 (пустые ячейки) не выбираются.
+func SelectLine(quotes []common.BookmakerOdds, cfg BookmakerConfig) LineChoice {
+	var odds PerOutcome
+	choice := LineChoice{Bookmakers: map[string]string{}}
+	for _, outcome := range Outcomes {
+		for _, quote := range quotes {
+			if odd := quote.Odd(outcome); odd > 1 && odd > odds.Get(outcome) {
+				odds.Set(outcome, odd)
+				choice.Bookmakers[outcome] = quote.Name
+			}
+		}
+		for _, quote := range quotes {
+			odd := quote.Odd(outcome)
+			if quote.Name == cfg.Preferred && odd > 1 && odd >= odds.Get(outcome)*(1-cfg.Tolerance) {
+				odds.Set(outcome, odd)
+				choice.Bookmakers[outcome] = quote.Name
+			}
+		}
+	}
+	choice.Odds = Odds{OddF: odds.F, OddX: odds.X, OddL: odds.L}
+	return choice
+}
+
+// baselineOdds возвращает коэффициенты букмекера для сравнения с выбором
+// линии; без него - коэффициенты события (колонки oddF, oddX, oddL)
+func baselineOdds(event common.Event, cfg BookmakerConfig) Odds {
+	for _, quote := range event.Bookmakers {
+		if name := cfg.baseline(); name != "" && quote.Name == name {
+			return Odds{OddF: quote.OddF, OddX: quote.OddX, OddL: quote.OddL}
+		}
+	}
+	return Odds{OddF: event.OddF, OddX: event.OddX, OddL: event.OddL}
+}
+
+// LineProvider провайдер коэффициентов, выбирающий линию среди нескольких букмекеров
+type LineProvider interface {
+	// Baseline возвращает коэффициенты одного букмекера для сравнения
+	// с выбранной линией (ok = false - у события одна линия)
+	Baseline(eventNumber int) (Odds, bool)
+}
+
+// lineValue дополнительный выигрыш рассчитанного события от выбора линии:
+// ставка на сыгравший исход, умноженная на разницу коэффициентов
+func lineValue(record TrainerRecord, baseline Odds) float64 {
+	result := record.Result
+	if result != "F" && result != "X" && result != "L" || baseline.Get(result) <= 0 {
+		return 0
+	}
+	odds := Odds{OddF: record.OddF, OddX: record.OddX, OddL: record.OddL}
+	bets := PerOutcome{F: record.BetF, X: record.BetX, L: record.BetL}
+	return bets.Get(result) * (odds.Get(result) - baseline.Get(result))
+}
+
+// BookmakerTurnover возвращает сумму ставок по букмекерам исходов
+// (TrainerRecord.Match.BookmakerF/X/L)
+func BookmakerTurnover(records []TrainerRecord) map[string]float64 {
+	turnover := map[string]float64{}
+	for i := range records {
+		bets := PerOutcome{F: records[i].BetF, X: records[i].BetX, L: records[i].BetL}
+		for _, outcome := range Outcomes {
+			name := *records[i].Match.Field("bookmaker" + outcome)
+			if name != "" && bets.Get(outcome) > 0 {
+				turnover[name] += bets.Get(outcome)
+			}
+		}
+	}
+	return turnover
+}
@@ -0,0 +1,178 @@
+package trainer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ComparisonResult результат одной стратегии в сравнении
+type ComparisonResult struct {
+	Strategy string
+	Records  []TrainerRecord // Записи от старых к новым
+	Stats    Stats
+}
+
+// Comparison результаты нескольких стратегий на одних и тех же событиях
+// и коэффициентах
+type Comparison struct {
+	Events  []string // События от старых к новым
+	Odds    []Odds   // Коэффициенты событий от старых к новым
+	Results []ComparisonResult
+}
+
+// Compare прогоняет стратегии по одним событиям. Коэффициенты берутся из
+// provider один раз и затем отдаются каждой стратегии, поэтому все стратегии
+// видят одинаковые коэффициенты.
+func Compare(eventsFromOldest []string, provider OddsProvider, flags Flags, strategies []Strategy) Comparison {
+	odds := make([]Odds, len(eventsFromOldest))
+	for i := range odds {
+		odds[i] = provider.Odds(i + 1)
+	}
+
+	comparison := Comparison{Events: eventsFromOldest, Odds: odds}
+	for _, strategy := range strategies {
+		flags.Strategy = strategy.Name()
+		records := Simulate(eventsFromOldest, NewFixedOddsProvider(odds, provider, flags), flags, strategy)
+		comparison.Results = append(comparison.Results, ComparisonResult{
+			Strategy: strategy.Name(),
+			Records:  records,
+			Stats:    CalculateStats(records, eventsFromOldest[:len(records)]),
+		})
+	}
+	return comparison
+}
+
+// PrintComparison выводит сводную таблицу статистики стратегий
+func PrintComparison(c Comparison) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("                 📊 СРАВНЕНИЕ СТРАТЕГИЙ")
+	fmt.Println(strings.Repeat("=", 60))
+
+	fmt.Printf("\n%-24s", "")
+	for _, result := range c.Results {
+		fmt.Printf(" %16s", result.Strategy)
+	}
+	fmt.Println()
+
+	row := func(title string, value func(result ComparisonResult) string) {
+		fmt.Printf("%-24s", title)
+		for _, result := range c.Results {
+			fmt.Printf(" %16s", value(result))
+		}
+		fmt.Println()
+	}
+	amount := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', 0, 64)
+	}
+
+	row("Событий", func(r ComparisonResult) string { return strconv.Itoa(len(r.Records)) })
+	row("Итоговый результат", func(r ComparisonResult) string {
+		if len(r.Records) == 0 {
+			return "-"
+		}
+		return amount(r.Records[len(r.Records)-1].Total)
+	})
+	row("Итоговый капитал", func(r ComparisonResult) string { return amount(r.Stats.Drawdown.FinalEquity) })
+	row("Макс. просадка", func(r ComparisonResult) string { return amount(r.Stats.Drawdown.MaxDrawdown) })
+	for _, outcome := range Outcomes {
+		outcome := outcome
+		row("Макс. ставка "+outcome, func(r ComparisonResult) string { return amount(r.Stats.MaxBets[outcome]) })
+	}
+	for _, outcome := range Outcomes {
+		outcome := outcome
+		row("Макс. убыток "+outcome, func(r ComparisonResult) string { return amount(r.Stats.MaxLosses[outcome]) })
+	}
+
+	patterns := map[string]bool{}
+	for _, result := range c.Results {
+		for pattern := range result.Stats.PatternCounts {
+			patterns[pattern] = true
+		}
+	}
+	names := make([]string, 0, len(patterns))
+	for pattern := range patterns {
+		names = append(names, pattern)
+	}
+	sort.Strings(names)
+	for _, pattern := range names {
+		pattern := pattern
+		row("Паттерн "+pattern, func(r ComparisonResult) string { return strconv.Itoa(r.Stats.PatternCounts[pattern]) })
+	}
+
+	for _, result := range c.Results {
+		if result.Stats.RuinedAt > 0 {
+			fmt.Printf("\n💥 %s: разорение на событии %d", result.Strategy, result.Stats.RuinedAt)
+		} else if result.Stats.StoppedAt > 0 {
+			fmt.Printf("\n⛔ %s: расчет остановлен на событии %d", result.Strategy, result.Stats.StoppedAt)
+		}
+	}
+	fmt.Println()
+}
+
+// SaveComparisonCSV сохраняет сравнение в широкий CSV: общие колонки события
+// и по набору колонок "<стратегия>.<колонка>" на каждую стратегию.
+// События идут от новых к старым, как в SaveToCSV. Если расчет стратегии
+// остановлен раньше, ее колонки на следующих событиях пустые.
+func SaveComparisonCSV(c Comparison, filename string, meta map[string]string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := writeMetaLine(file, meta); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	columns := []string{"betF", "betX", "betL", "lossF", "lossX", "lossL", "total", "equity", "pattern"}
+	headers := []string{"event_number", "result", "oddF", "oddX", "oddL"}
+	equity := make([]map[int]float64, len(c.Results))
+	for i, result := range c.Results {
+		for _, column := range columns {
+			headers = append(headers, result.Strategy+"."+column)
+		}
+		equity[i] = map[int]float64{}
+		for _, point := range EquityCurve(result.Records) {
+			equity[i][point.EventNumber] = point.Equity
+		}
+	}
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+
+	amount := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', 0, 64)
+	}
+	for i := len(c.Events) - 1; i >= 0; i-- {
+		row := []string{
+			strconv.Itoa(i + 1),
+			c.Events[i],
+			strconv.FormatFloat(c.Odds[i].OddF, 'f', 2, 64),
+			strconv.FormatFloat(c.Odds[i].OddX, 'f', 2, 64),
+			strconv.FormatFloat(c.Odds[i].OddL, 'f', 2, 64),
+		}
+		for j, result := range c.Results {
+			if i >= len(result.Records) {
+				row = append(row, make([]string, len(columns))...)
+				continue
+			}
+			record := result.Records[i]
+			row = append(row,
+				amount(record.BetF), amount(record.BetX), amount(record.BetL),
+				amount(record.LossF), amount(record.LossX), amount(record.LossL),
+				amount(record.Total), amount(equity[j][record.EventNumber]), record.Pattern)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return writer.Error()
+}
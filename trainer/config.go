@@ -0,0 +1,224 @@
+package trainer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config содержит конфигурацию тренажера
+type Config struct {
+	DefaultBetF float64                        `json:"default_bet" yaml:"default_bet"`
+	RoundUp     float64                        `json:"round_up" yaml:"round_up"`
+	OddsRanges  OddsRanges                     `json:"odds_ranges" yaml:"odds_ranges"`
+	Patterns    PatternsConfig                 `json:"patterns" yaml:"patterns"`
+	Bankroll    BankrollConfig                 `json:"bankroll" yaml:"bankroll"`
+	Bookmakers  BookmakerConfig                `json:"bookmakers,omitempty" yaml:"bookmakers,omitempty"` // Выбор букмекера при коэффициентах нескольких букмекеров
+	Strategies  map[string]Params              `json:"strategies,omitempty" yaml:"strategies,omitempty"` // Параметры стратегий по имени
+	Reactions   map[string]map[string]Reaction `json:"reactions,omitempty" yaml:"reactions,omitempty"`   // Реакции стратегий на паттерны по ID паттерна или серьезности
+	Tags        []string                       `json:"tags,omitempty" yaml:"tags,omitempty"`             // Теги real-games файлов в дополнение к BuiltinTags
+}
+
+// OddsRanges диапазоны генерируемых коэффициентов и маржи
+type OddsRanges struct {
+	OddF        Range `json:"odd_f" yaml:"odd_f"`
+	OddX        Range `json:"odd_x" yaml:"odd_x"`
+	OddL        Range `json:"odd_l" yaml:"odd_l"`
+	MarginRange Range `json:"margin" yaml:"margin"`
+}
+
+// PatternsConfig пороги паттернов в долях базовой ставки и дополнительные правила
+type PatternsConfig struct {
+	SmallMult float64         `json:"small_mult" yaml:"small_mult"`                   // Малый порог: SmallMult * DefaultBetF
+	BigMult   float64         `json:"big_mult" yaml:"big_mult"`                       // Большой порог: BigMult * DefaultBetF
+	Rules     []ThresholdRule `json:"rules,omitempty" yaml:"rules,omitempty"`         // Правила паттернов; ID встроенного правила заменяет его
+	Sequences []SequenceRule  `json:"sequences,omitempty" yaml:"sequences,omitempty"` // Правила формы серий; ID встроенного правила заменяет его
+	Builtin   []string        `json:"builtin,omitempty" yaml:"builtin,omitempty"`     // ID включаемых BuiltinSequenceRules
+}
+
+// Range представляет диапазон значений
+type Range struct {
+	Min float64 `json:"min" yaml:"min"`
+	Max float64 `json:"max" yaml:"max"`
+}
+
+// DefaultConfig возвращает конфигурацию по умолчанию
+func DefaultConfig() Config {
+	return Config{
+		DefaultBetF: DEFAULT_BET,
+		RoundUp:     50,
+		OddsRanges: OddsRanges{
+			OddF:        Range{Min: 1.8, Max: 2.1},
+			OddX:        Range{Min: 3.3, Max: 3.9},
+			OddL:        Range{Min: 4.0, Max: 5.0},
+			MarginRange: Range{Min: 1.05, Max: 1.1},
+		},
+		Patterns: PatternsConfig{
+			SmallMult: 10,
+			BigMult:   20,
+		},
+		Bankroll: BankrollConfig{
+			OnCap: OnCapStop,
+		},
+	}
+}
+
+// LoadConfig читает конфигурацию из JSON или YAML файла (по расширению).
+// Незаданные в файле значения берутся из DefaultConfig.
+func LoadConfig(filename string) (Config, error) {
+	cfg := DefaultConfig()
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return cfg, err
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&cfg); err != nil {
+			return cfg, fmt.Errorf("invalid config %s: %v", filename, err)
+		}
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&cfg); err != nil {
+			return cfg, fmt.Errorf("invalid config %s: %v", filename, err)
+		}
+	default:
+		return cfg, fmt.Errorf("unsupported config format %s: expected .json, .yaml or .yml", filename)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("invalid config %s: %v", filename, err)
+	}
+
+	return cfg, nil
+}
+
+// Validate проверяет согласованность конфигурации
+func (c Config) Validate() error {
+	if c.DefaultBetF <= 0 {
+		return fmt.Errorf("default_bet must be positive, got %v", c.DefaultBetF)
+	}
+	if c.RoundUp <= 0 {
+		return fmt.Errorf("round_up must be positive, got %v", c.RoundUp)
+	}
+
+	ranges := []struct {
+		name  string
+		value Range
+	}{
+		{"odd_f", c.OddsRanges.OddF},
+		{"odd_x", c.OddsRanges.OddX},
+		{"odd_l", c.OddsRanges.OddL},
+		{"margin", c.OddsRanges.MarginRange},
+	}
+	for _, r := range ranges {
+		if r.value.Min >= r.value.Max {
+			return fmt.Errorf("odds_ranges.%s: min %v must be less than max %v", r.name, r.value.Min, r.value.Max)
+		}
+		if r.value.Min <= 1 {
+			return fmt.Errorf("odds_ranges.%s: min %v must be greater than 1", r.name, r.value.Min)
+		}
+	}
+
+	// Маржа 1/oddF + 1/oddX + 1/oddL достижима только в этих пределах
+	odds := c.OddsRanges
+	lowest := 1/odds.OddF.Max + 1/odds.OddX.Max + 1/odds.OddL.Max
+	highest := 1/odds.OddF.Min + 1/odds.OddX.Min + 1/odds.OddL.Min
+	if odds.MarginRange.Max < lowest || odds.MarginRange.Min > highest {
+		return fmt.Errorf("odds_ranges.margin [%v, %v] is unreachable with the odds ranges: possible margin is [%.4f, %.4f]",
+			odds.MarginRange.Min, odds.MarginRange.Max, lowest, highest)
+	}
+
+	if c.Patterns.SmallMult <= 0 || c.Patterns.BigMult < c.Patterns.SmallMult {
+		return fmt.Errorf("patterns: expected 0 < small_mult <= big_mult, got %v and %v", c.Patterns.SmallMult, c.Patterns.BigMult)
+	}
+	for _, rule := range c.Patterns.Rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("patterns: %v", err)
+		}
+	}
+	for _, rule := range c.Patterns.Sequences {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("patterns: %v", err)
+		}
+	}
+	for _, id := range c.Patterns.Builtin {
+		if _, ok := builtinSequenceRule(id); !ok {
+			known := make([]string, len(BuiltinSequenceRules))
+			for i, rule := range BuiltinSequenceRules {
+				known[i] = rule.Name
+			}
+			return fmt.Errorf("patterns.builtin: unknown rule %q (known: %s)", id, strings.Join(known, ", "))
+		}
+	}
+
+	if err := c.Bankroll.Validate(); err != nil {
+		return err
+	}
+	if err := c.Bookmakers.Validate(); err != nil {
+		return err
+	}
+	for _, tag := range c.Tags {
+		if err := ValidateTag(tag); err != nil {
+			return fmt.Errorf("tags: %v", err)
+		}
+	}
+
+	for name, values := range c.Strategies {
+		strategy, err := GetStrategy(name)
+		if err != nil {
+			return fmt.Errorf("strategies: %v", err)
+		}
+		if err := ValidateParams(strategy, values); err != nil {
+			return fmt.Errorf("strategies: %v", err)
+		}
+	}
+
+	keys := map[string]bool{SeverityLow: true, SeverityMedium: true, SeverityHigh: true}
+	for _, rule := range PatternRules(c) {
+		keys[rule.ID()] = true
+	}
+	for name, reactions := range c.Reactions {
+		if _, err := GetStrategy(name); err != nil {
+			return fmt.Errorf("reactions: %v", err)
+		}
+		for key, reaction := range reactions {
+			if !keys[key] {
+				return fmt.Errorf("reactions: %s: %q is neither a pattern ID nor a severity", name, key)
+			}
+			if err := reaction.Validate(); err != nil {
+				return fmt.Errorf("reactions: %s.%s: %v", name, key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// EffectiveConfig возвращает конфигурацию запуска или DefaultConfig, если она не задана
+func (f Flags) EffectiveConfig() Config {
+	if f.Config != nil {
+		return *f.Config
+	}
+	return DefaultConfig()
+}
+
+// roundUp округляет значение вверх до кратного c.RoundUp
+func (c Config) roundUp(value float64) float64 {
+	return math.Ceil(value/c.RoundUp) * c.RoundUp
+}
+
+// calcBet вычисляет ставку
+func (c Config) calcBet(value, odd float64) float64 {
+	return c.roundUp(value / (odd - 1))
+}
@@ -0,0 +1,484 @@
+package trainer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/holygun/go-trainer/common"
+)
+
+// CSVSchemaVersion версия формата CSV с записями тренажера. Записывается
+// в строку параметров ("# schema=2 ..."); файлы без нее считаются версией 1.
+//
+// Версия 1: 16 основных колонок по позициям, заголовок необязателен.
+// Версия 2: колонки определяются по заголовку, порядок любой, неизвестные
+// колонки пропускаются - файлы новых версий читаются старым кодом и наоборот.
+const CSVSchemaVersion = 2
+
+// schemaMetaKey ключ версии формата в строке параметров
+const schemaMetaKey = "schema"
+
+// CSVError ошибка разбора CSV файла с указанием места
+type CSVError struct {
+	File   string
+	Line   int    // Строка файла (с 1), 0 - ошибка относится ко всему файлу
+	Column int    // Колонка (с 1), 0 - ошибка относится ко всей строке
+	Name   string // Имя колонки по заголовку
+	Err    error
+}
+
+func (e *CSVError) Error() string {
+	switch {
+	case e.Column > 0 && e.Name != "":
+		return fmt.Sprintf("%s:%d: column %d (%s): %v", e.File, e.Line, e.Column, e.Name, e.Err)
+	case e.Column > 0:
+		return fmt.Sprintf("%s:%d: column %d: %v", e.File, e.Line, e.Column, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.File, e.Err)
+}
+
+func (e *CSVError) Unwrap() error { return e.Err }
+
+// csvColumn колонка CSV: как записать значение записи и как его прочитать
+type csvColumn struct {
+	name   string
+	format func(record *TrainerRecord) string
+	parse  func(record *TrainerRecord, value string) error // nil - колонка вычисляется и не читается
+}
+
+func floatColumn(name string, precision int, field func(record *TrainerRecord) *float64) csvColumn {
+	return csvColumn{
+		name: name,
+		format: func(record *TrainerRecord) string {
+			return strconv.FormatFloat(*field(record), 'f', precision, 64)
+		},
+		parse: func(record *TrainerRecord, value string) error {
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid number %q", value)
+			}
+			*field(record) = number
+			return nil
+		},
+	}
+}
+
+func textColumn(name string, field func(record *TrainerRecord) *string) csvColumn {
+	return csvColumn{
+		name:   name,
+		format: func(record *TrainerRecord) string { return *field(record) },
+		parse: func(record *TrainerRecord, value string) error {
+			*field(record) = value
+			return nil
+		},
+	}
+}
+
+// baseCSVColumns основные колонки в порядке записи (и в порядке позиций версии 1)
+var baseCSVColumns = []csvColumn{
+	{
+		name:   "event_number",
+		format: func(record *TrainerRecord) string { return strconv.Itoa(record.EventNumber) },
+		parse: func(record *TrainerRecord, value string) error {
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid event number %q", value)
+			}
+			record.EventNumber = number
+			return nil
+		},
+	},
+	textColumn("result", func(r *TrainerRecord) *string { return &r.Result }),
+	floatColumn("oddF", 2, func(r *TrainerRecord) *float64 { return &r.OddF }),
+	floatColumn("oddX", 2, func(r *TrainerRecord) *float64 { return &r.OddX }),
+	floatColumn("oddL", 2, func(r *TrainerRecord) *float64 { return &r.OddL }),
+	floatColumn("betF", 0, func(r *TrainerRecord) *float64 { return &r.BetF }),
+	floatColumn("betX", 0, func(r *TrainerRecord) *float64 { return &r.BetX }),
+	floatColumn("betL", 0, func(r *TrainerRecord) *float64 { return &r.BetL }),
+	floatColumn("lossF", 0, func(r *TrainerRecord) *float64 { return &r.LossF }),
+	floatColumn("lossX", 0, func(r *TrainerRecord) *float64 { return &r.LossX }),
+	floatColumn("lossL", 0, func(r *TrainerRecord) *float64 { return &r.LossL }),
+	floatColumn("total", 0, func(r *TrainerRecord) *float64 { return &r.Total }),
+	floatColumn("uf", 0, func(r *TrainerRecord) *float64 { return &r.UF }),
+	floatColumn("ux", 0, func(r *TrainerRecord) *float64 { return &r.UX }),
+	floatColumn("ul", 0, func(r *TrainerRecord) *float64 { return &r.UL }),
+	textColumn("pattern", func(r *TrainerRecord) *string { return &r.Pattern }),
+}
+
+var (
+	bankrollCSVColumn = floatColumn("bankroll", 0, func(r *TrainerRecord) *float64 { return &r.Bankroll })
+	limitCSVColumn    = textColumn("limit", func(r *TrainerRecord) *string { return &r.Limit })
+	reactionCSVColumn = textColumn("reaction", func(r *TrainerRecord) *string { return &r.Reaction })
+	// line_value пишется вместе с колонками матча, если линия выбиралась среди букмекеров
+	lineValueCSVColumn = floatColumn("line_value", 0, func(r *TrainerRecord) *float64 { return &r.LineValue })
+)
+
+// hasLineValue сообщает, есть ли в записях выигрыш от выбора линии
+func hasLineValue(records []TrainerRecord) bool {
+	for _, record := range records {
+		if record.LineValue != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// traceCSVColumn колонка trace.<name> широкого CSV; пустая ячейка - значение не записано
+func traceCSVColumn(name string) csvColumn {
+	return csvColumn{
+		name: traceColumnPrefix + name,
+		format: func(record *TrainerRecord) string {
+			value, _ := record.Trace.Get(name)
+			return value
+		},
+		parse: func(record *TrainerRecord, value string) error {
+			if value != "" {
+				record.Trace.AddText(name, value)
+			}
+			return nil
+		},
+	}
+}
+
+// matchCSVColumn колонка метаданных матча (date, home, ... из common.MatchColumns)
+func matchCSVColumn(name string) csvColumn {
+	return textColumn(name, func(r *TrainerRecord) *string { return r.Match.Field(name) })
+}
+
+// lookupCSVColumn возвращает известную колонку по имени из заголовка
+func lookupCSVColumn(name string) (csvColumn, bool) {
+	for _, column := range baseCSVColumns {
+		if column.name == name {
+			return column, true
+		}
+	}
+	switch name {
+	case "bankroll":
+		return bankrollCSVColumn, true
+	case "limit":
+		return limitCSVColumn, true
+	case "reaction":
+		return reactionCSVColumn, true
+	case "line_value":
+		return lineValueCSVColumn, true
+	case "equity":
+		return csvColumn{name: name}, true
+	}
+	if (&common.Match{}).Field(name) != nil {
+		return matchCSVColumn(name), true
+	}
+	if trace, ok := strings.CutPrefix(name, traceColumnPrefix); ok && trace != "" {
+		return traceCSVColumn(trace), true
+	}
+	return csvColumn{}, false
+}
+
+// isCSVHeader сообщает, является ли строка заголовком (в версии 2 колонка
+// event_number может стоять не первой)
+func isCSVHeader(row []string) bool {
+	for _, cell := range row {
+		if cell == "event_number" {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadCSV читает CSV файл и возвращает записи
+func ReadCSV(filename string) ([]TrainerRecord, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return DecodeCSV(file, filename)
+}
+
+// DecodeCSV читает записи из CSV; name используется в ошибках вместо имени файла
+func DecodeCSV(r io.Reader, name string) ([]TrainerRecord, error) {
+	input := bufio.NewReader(r)
+	meta, metaLines, err := readMetaLines(input)
+	if err != nil {
+		return nil, &CSVError{File: name, Err: err}
+	}
+
+	version := 1
+	if value, ok := meta[schemaMetaKey]; ok {
+		version, err = strconv.Atoi(value)
+		if err != nil || version < 1 {
+			return nil, &CSVError{File: name, Line: 1, Err: fmt.Errorf("invalid schema version %q", value)}
+		}
+	}
+
+	reader := csv.NewReader(input)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	// Номер строки файла для строки, прочитанной csv.Reader
+	fileLine := func(field int) int {
+		line, _ := reader.FieldPos(field)
+		return metaLines + line
+	}
+	readError := func(err error) error {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return &CSVError{File: name, Line: metaLines + parseErr.Line, Err: parseErr.Err}
+		}
+		return &CSVError{File: name, Err: err}
+	}
+
+	row, err := reader.Read()
+	if err == io.EOF {
+		return []TrainerRecord{}, nil
+	}
+	if err != nil {
+		return nil, readError(err)
+	}
+
+	var columns []csvColumn
+	if isCSVHeader(row) {
+		columns = make([]csvColumn, len(row))
+		present := map[string]bool{}
+		for j, header := range row {
+			// Неизвестные колонки (из более новых версий) пропускаются
+			columns[j], _ = lookupCSVColumn(header)
+			present[header] = true
+		}
+		for _, column := range baseCSVColumns {
+			if !present[column.name] {
+				return nil, &CSVError{File: name, Line: fileLine(0), Err: fmt.Errorf("missing required column %q", column.name)}
+			}
+		}
+		row, err = reader.Read()
+	} else if version >= 2 {
+		return nil, &CSVError{File: name, Line: fileLine(0), Err: fmt.Errorf("missing header (schema version %d)", version)}
+	} else {
+		columns = baseCSVColumns
+	}
+
+	records := []TrainerRecord{}
+	for ; err == nil; row, err = reader.Read() {
+		if len(row) < len(columns) {
+			return nil, &CSVError{File: name, Line: fileLine(0),
+				Err: fmt.Errorf("expected %d columns, got %d", len(columns), len(row))}
+		}
+
+		var record TrainerRecord
+		for j, column := range columns {
+			if column.parse == nil {
+				continue
+			}
+			if err := column.parse(&record, row[j]); err != nil {
+				return nil, &CSVError{File: name, Line: fileLine(j), Column: j + 1, Name: column.name, Err: err}
+			}
+		}
+		records = append(records, record)
+	}
+	if err != io.EOF {
+		return nil, readError(err)
+	}
+
+	return records, nil
+}
+
+// CSVOptions дополнительные данные выходного CSV файла
+type CSVOptions struct {
+	Meta     map[string]string // Параметры запуска для строки-комментария "# key=value key=value"
+	Bankroll bool              // Колонки bankroll и limit
+	Equity   bool              // Колонка equity - капитал с учетом неотыгранных убытков
+	Reaction bool              // Колонка reaction - примененная реакция на паттерн
+	Trace    bool              // Широкий CSV: колонки trace.<имя> с промежуточными значениями стратегии
+	Match    bool              // Колонки метаданных матча: date, home, away, league, sport, bookmakerF/X/L, note (и line_value)
+}
+
+// csvColumns возвращает колонки выходного файла: основные и включенные в opts
+func csvColumns(records []TrainerRecord, opts CSVOptions) []csvColumn {
+	columns := append([]csvColumn{}, baseCSVColumns...)
+	if opts.Bankroll {
+		columns = append(columns, bankrollCSVColumn, limitCSVColumn)
+	}
+	if opts.Equity {
+		equity := map[int]float64{}
+		for _, point := range EquityCurve(records) {
+			equity[point.EventNumber] = point.Equity
+		}
+		columns = append(columns, csvColumn{
+			name: "equity",
+			format: func(record *TrainerRecord) string {
+				return strconv.FormatFloat(equity[record.EventNumber], 'f', 0, 64)
+			},
+		})
+	}
+	if opts.Reaction {
+		columns = append(columns, reactionCSVColumn)
+	}
+	if opts.Match {
+		for _, name := range common.MatchColumns {
+			columns = append(columns, matchCSVColumn(name))
+		}
+		if hasLineValue(records) {
+			columns = append(columns, lineValueCSVColumn)
+		}
+	}
+	if opts.Trace {
+		for _, name := range TraceColumns(records) {
+			columns = append(columns, traceCSVColumn(name))
+		}
+	}
+	return columns
+}
+
+// SaveToCSV сохраняет записи в CSV файл
+func SaveToCSV(records []TrainerRecord, filename string) error {
+	return SaveToCSVWithOptions(records, filename, CSVOptions{})
+}
+
+// SaveToCSVWithOptions сохраняет записи в CSV файл, записывая перед заголовками
+// строку-комментарий с версией формата и параметрами запуска и добавляя
+// включенные колонки
+func SaveToCSVWithOptions(records []TrainerRecord, filename string, opts CSVOptions) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	meta := map[string]string{schemaMetaKey: strconv.Itoa(CSVSchemaVersion)}
+	for key, value := range opts.Meta {
+		meta[key] = value
+	}
+	if err := writeMetaLine(file, meta); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(file)
+	columns := csvColumns(records, opts)
+	headers := make([]string, len(columns))
+	for j, column := range columns {
+		headers[j] = column.name
+	}
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+
+	for i := range records {
+		row := make([]string, len(columns))
+		for j, column := range columns {
+			row[j] = column.format(&records[i])
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeMetaLine записывает строку-комментарий "# key=value key=value"
+// с отсортированными ключами (ничего не пишет для пустых метаданных).
+// Пустые значения и значения с пробелами или кавычками записываются в
+// кавычках Go (strconv.Quote): пути -odds-from и -config могут их содержать.
+func writeMetaLine(w io.Writer, meta map[string]string) error {
+	if len(meta) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		value := meta[key]
+		if value == "" || strings.ContainsAny(value, " \t\r\n\"") {
+			value = strconv.Quote(value)
+		}
+		pairs[i] = key + "=" + value
+	}
+	_, err := fmt.Fprintf(w, "# %s\n", strings.Join(pairs, " "))
+	return err
+}
+
+// readMetaLines читает строки-комментарии "# key=value key=value" в начале
+// файла и возвращает параметры и количество прочитанных строк
+func readMetaLines(r *bufio.Reader) (map[string]string, int, error) {
+	meta := map[string]string{}
+	lines := 0
+	for {
+		next, err := r.Peek(1)
+		if err == io.EOF || (err == nil && next[0] != '#') {
+			return meta, lines, nil
+		}
+		if err != nil {
+			return nil, lines, err
+		}
+
+		line, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, lines, err
+		}
+		lines++
+		if err := parseMetaLine(strings.TrimPrefix(line, "#"), meta); err != nil {
+			return nil, lines, fmt.Errorf("meta line %d: %v", lines, err)
+		}
+	}
+}
+
+// parseMetaLine разбирает пары key=value строки-комментария в meta; значение
+// в кавычках (см. writeMetaLine) может содержать пробелы
+func parseMetaLine(line string, meta map[string]string) error {
+	for {
+		line = strings.TrimLeft(line, " \t\r\n")
+		if line == "" {
+			return nil
+		}
+		end := strings.IndexAny(line, " \t\r\n=")
+		if end < 0 || line[end] != '=' {
+			// Слово без "=" пропускается
+			if end < 0 {
+				return nil
+			}
+			line = line[end:]
+			continue
+		}
+		key, rest := line[:end], line[end+1:]
+		if strings.HasPrefix(rest, "\"") {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return fmt.Errorf("invalid quoted value of %s: %v", key, err)
+			}
+			value, _ := strconv.Unquote(quoted)
+			meta[key], line = value, rest[len(quoted):]
+			continue
+		}
+		end = strings.IndexAny(rest, " \t\r\n")
+		if end < 0 {
+			end = len(rest)
+		}
+		meta[key], line = rest[:end], rest[end:]
+	}
+}
+
+// ReadCSVMeta читает параметры запуска из строк-комментариев "# key=value key=value"
+// в начале CSV файла (пустой результат, если строк нет)
+func ReadCSVMeta(filename string) (map[string]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	meta, _, err := readMetaLines(bufio.NewReader(file))
+	return meta, err
+}
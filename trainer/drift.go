@@ -0,0 +1,237 @@
+package trainer
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DriftField поле записи, изменившееся при пересчете (значения в формате CSV)
+type DriftField struct {
+	Name string `json:"name"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// Drift расхождение пересчитанного .input файла с сохраненными записями
+// (.actual или .expected)
+type Drift struct {
+	Input        string       `json:"input"`
+	Stored       string       `json:"stored,omitempty"`
+	Strategy     string       `json:"strategy,omitempty"`
+	Events       int          `json:"events"`                // Событий в пересчете
+	StoredEvents int          `json:"stored_events"`         // Событий в сохраненном файле
+	Changed      int          `json:"changed"`               // Событий с отличиями
+	FirstEvent   int          `json:"first_event,omitempty"` // Первое событие с отличиями (0 - отличий нет)
+	Fields       []DriftField `json:"fields,omitempty"`      // Отличия первого события
+	OldTotal     float64      `json:"old_total"`             // Итог последнего события
+	NewTotal     float64      `json:"new_total"`
+	OldPeak      PerOutcome   `json:"old_peak"` // Максимальные ставки по исходам
+	NewPeak      PerOutcome   `json:"new_peak"`
+	Error        string       `json:"error,omitempty"` // Файл не удалось пересчитать или прочитать
+}
+
+// HasDrift сообщает, отличается ли пересчет от сохраненных записей
+func (d Drift) HasDrift() bool {
+	return d.Changed > 0
+}
+
+// StoredResultsPath возвращает сохраненные записи для .input файла: .actual,
+// а без него .expected ("" - нет ни одного)
+func StoredResultsPath(inputFile string) string {
+	base := strings.TrimSuffix(inputFile, ".input")
+	for _, suffix := range []string{".actual", ".expected"} {
+		if _, err := os.Stat(base + suffix); err == nil {
+			return base + suffix
+		}
+	}
+	return ""
+}
+
+// RecomputeRealGame рассчитывает .input файл так же, как trainer real:
+// стратегия и теги из имени файла, настройки из .meta поверх base. Имена
+// регрессионных тестов "<стратегия>_<номер>_<описание>.input" тоже
+// поддерживаются. Параметры стратегии, записанные в сохраненный .actual или
+// .expected, применяются поверх .meta, а params - поверх них (как -param).
+func RecomputeRealGame(inputFile string, base Config, params Params) ([]TrainerRecord, Strategy, error) {
+	strategyName, tags := ParseRealGameName(inputFile)
+	strategyName, _, _ = strings.Cut(strategyName, "_")
+	meta, err := LoadRealGameMeta(inputFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	settings, err := meta.Resolve(strategyName, HasTag(append(tags, meta.Tags...), TagHockey), base)
+	if err != nil {
+		return nil, nil, err
+	}
+	strategy, cfg := settings.Strategy, settings.Config
+
+	if stored := StoredResultsPath(inputFile); stored != "" {
+		storedMeta, err := ReadCSVMeta(stored)
+		if err != nil {
+			return nil, nil, err
+		}
+		recorded, err := RecordedParams(strategy, storedMeta)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", stored, err)
+		}
+		for name, value := range recorded {
+			cfg.SetParam(strategy.Name(), name, value)
+		}
+	}
+	for name, value := range params {
+		cfg.SetParam(strategy.Name(), name, value)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	events, err := ReadInputFile(inputFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	flags := Flags{Strategy: strategy.Name(), Hockey: settings.Hockey, Real: true, Config: &cfg}
+	return GenerateRecordsFromEvents(events, flags, strategy), strategy, nil
+}
+
+// CompareRecords сравнивает сохраненные записи с пересчитанными по номерам
+// событий (порядок записей в файлах не важен) по основным колонкам CSV
+func CompareRecords(stored, recomputed []TrainerRecord) Drift {
+	drift := Drift{
+		Events:       len(recomputed),
+		StoredEvents: len(stored),
+		OldTotal:     lastTotal(stored),
+		NewTotal:     lastTotal(recomputed),
+		OldPeak:      peakBets(stored),
+		NewPeak:      peakBets(recomputed),
+	}
+
+	byNumber := func(records []TrainerRecord) map[int]*TrainerRecord {
+		index := map[int]*TrainerRecord{}
+		for i := range records {
+			index[records[i].EventNumber] = &records[i]
+		}
+		return index
+	}
+	old, new := byNumber(stored), byNumber(recomputed)
+	numbers := []int{}
+	for number := range old {
+		numbers = append(numbers, number)
+	}
+	for number := range new {
+		if old[number] == nil {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Ints(numbers)
+
+	for _, number := range numbers {
+		fields := compareRecord(old[number], new[number])
+		if len(fields) == 0 {
+			continue
+		}
+		drift.Changed++
+		if drift.FirstEvent == 0 {
+			drift.FirstEvent = number
+			drift.Fields = fields
+		}
+	}
+	return drift
+}
+
+// compareRecord возвращает отличающиеся основные колонки записи; запись,
+// которой нет в одном из файлов, отличается колонкой event_number
+func compareRecord(old, new *TrainerRecord) []DriftField {
+	if old == nil || new == nil {
+		field := DriftField{Name: "event_number", Old: "-", New: "-"}
+		if old != nil {
+			field.Old = strconv.Itoa(old.EventNumber)
+		} else {
+			field.New = strconv.Itoa(new.EventNumber)
+		}
+		return []DriftField{field}
+	}
+	var fields []DriftField
+	for _, column := range baseCSVColumns[1:] {
+		if before, after := column.format(old), column.format(new); before != after {
+			fields = append(fields, DriftField{Name: column.name, Old: before, New: after})
+		}
+	}
+	return fields
+}
+
+// lastTotal итог записи с наибольшим номером события
+func lastTotal(records []TrainerRecord) float64 {
+	number, total := 0, 0.0
+	for _, record := range records {
+		if record.EventNumber >= number {
+			number, total = record.EventNumber, record.Total
+		}
+	}
+	return total
+}
+
+// peakBets максимальные ставки по исходам
+func peakBets(records []TrainerRecord) PerOutcome {
+	var peak PerOutcome
+	for _, record := range records {
+		peak.F = max(peak.F, record.BetF)
+		peak.X = max(peak.X, record.BetX)
+		peak.L = max(peak.L, record.BetL)
+	}
+	return peak
+}
+
+// PrintDrift выводит таблицу расхождений и отличия первого события каждого файла
+func PrintDrift(drifts []Drift) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("                 🔍 РАСХОЖДЕНИЯ ПЕРЕСЧЕТА")
+	fmt.Println(strings.Repeat("=", 60))
+
+	amount := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', 0, 64)
+	}
+	change := func(old, new float64) string {
+		if old == new {
+			return amount(new)
+		}
+		return fmt.Sprintf("%s -> %s (%+.0f)", amount(old), amount(new), new-old)
+	}
+
+	width := len("ФАЙЛ")
+	for _, drift := range drifts {
+		width = max(width, len(drift.Input))
+	}
+	fmt.Printf("\n%-*s %8s %9s %8s  %s\n", width, "ФАЙЛ", "СОБЫТИЙ", "РАЗЛИЧИЙ", "ПЕРВОЕ", "ИТОГ")
+	for _, drift := range drifts {
+		if drift.Error != "" {
+			fmt.Printf("%-*s ❌ %s\n", width, drift.Input, drift.Error)
+			continue
+		}
+		first := "-"
+		if drift.FirstEvent > 0 {
+			first = strconv.Itoa(drift.FirstEvent)
+		}
+		fmt.Printf("%-*s %8d %9d %8s  %s\n", width, drift.Input, drift.Events, drift.Changed, first, change(drift.OldTotal, drift.NewTotal))
+	}
+
+	for _, drift := range drifts {
+		if !drift.HasDrift() {
+			continue
+		}
+		fmt.Printf("\n%s (сравнение с %s, стратегия %s)\n", drift.Input, drift.Stored, drift.Strategy)
+		if drift.Events != drift.StoredEvents {
+			fmt.Printf("   Событий: %d -> %d\n", drift.StoredEvents, drift.Events)
+		}
+		fmt.Printf("   Событие %d:\n", drift.FirstEvent)
+		for _, field := range drift.Fields {
+			fmt.Printf("      %-12s %12s -> %s\n", field.Name, field.Old, field.New)
+		}
+		fmt.Printf("   Итог: %s\n", change(drift.OldTotal, drift.NewTotal))
+		for _, outcome := range Outcomes {
+			fmt.Printf("   Макс. ставка %s: %s\n", outcome, change(drift.OldPeak.Get(outcome), drift.NewPeak.Get(outcome)))
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package trainer
+
+import "sort"
+
+// EquityPoint значение капитала после события
+type EquityPoint struct {
+	EventNumber int
+	Equity      float64
+}
+
+// Equity возвращает капитал после события по рыночной оценке: итог Total за
+// вычетом еще не отыгранных убытков и денег в ставках на несыгранное событие.
+// Для несыгранного события (Result "N") убытки в записи не заполнены, поэтому
+// берутся убытки предыдущей записи.
+func Equity(current, previous TrainerRecord) float64 {
+	if current.Result == "N" {
+		staked := current.BetF + current.BetX + current.BetL
+		return current.Total - outstandingLosses(previous) - staked
+	}
+	return current.Total - outstandingLosses(current)
+}
+
+// outstandingLosses сумма неотыгранных убытков записи (отрицательные
+// значения - метки несыгранного события - не учитываются)
+func outstandingLosses(record TrainerRecord) float64 {
+	sum := 0.0
+	for _, loss := range []float64{record.LossF, record.LossX, record.LossL} {
+		if loss > 0 {
+			sum += loss
+		}
+	}
+	return sum
+}
+
+// EquityCurve строит кривую капитала по записям в любом порядке.
+// Точки упорядочены от старых событий к новым.
+func EquityCurve(records []TrainerRecord) []EquityPoint {
+	sorted := make([]TrainerRecord, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].EventNumber < sorted[j].EventNumber
+	})
+
+	curve := make([]EquityPoint, len(sorted))
+	previous := TrainerRecord{}
+	for i, record := range sorted {
+		curve[i] = EquityPoint{EventNumber: record.EventNumber, Equity: Equity(record, previous)}
+		previous = record
+	}
+	return curve
+}
+
+// DrawdownStats статистика просадок кривой капитала
+type DrawdownStats struct {
+	FinalEquity      float64 // Капитал после последнего события
+	MinEquity        float64 // Минимальный капитал
+	PeakEquity       float64 // Максимальный капитал
+	MaxDrawdown      float64 // Максимальное падение от пика
+	MaxDrawdownAt    int     // Событие с максимальной просадкой
+	UnderwaterEvents int     // Количество событий ниже предыдущего пика
+	LongestRecovery  int     // Самый долгий период под водой, завершившийся новым пиком, событий
+	Unrecovered      int     // Событий под водой к концу расчета (0 - пик восстановлен)
+}
+
+// CalculateDrawdown вычисляет просадки кривой капитала. Начальный капитал
+// считается равным нулю.
+func CalculateDrawdown(curve []EquityPoint) DrawdownStats {
+	var stats DrawdownStats
+	peak := 0.0
+	peakIndex := -1 // Индекс события с пиком (-1 - начальный капитал)
+
+	for i, point := range curve {
+		if i == 0 || point.Equity < stats.MinEquity {
+			stats.MinEquity = point.Equity
+		}
+		if point.Equity >= peak {
+			if recovery := i - peakIndex - 1; recovery > stats.LongestRecovery {
+				stats.LongestRecovery = recovery
+			}
+			peak = point.Equity
+			peakIndex = i
+			continue
+		}
+
+		stats.UnderwaterEvents++
+		if drawdown := peak - point.Equity; drawdown > stats.MaxDrawdown {
+			stats.MaxDrawdown = drawdown
+			stats.MaxDrawdownAt = point.EventNumber
+		}
+	}
+
+	if len(curve) > 0 {
+		stats.FinalEquity = curve[len(curve)-1].Equity
+		stats.Unrecovered = len(curve) - peakIndex - 1
+	}
+	stats.PeakEquity = peak
+	return stats
+}
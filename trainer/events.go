@@ -0,0 +1,194 @@
+package trainer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level важность события тренажера
+type Level int
+
+const (
+	LevelDebug Level = iota // Внутренние шаги расчета
+	LevelInfo               // Рассчитанные события и списания
+	LevelWarn               // Паттерны и сработавшие лимиты
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel разбирает уровень debug, info, warn или error
+func ParseLevel(s string) (Level, error) {
+	for i, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q (expected %s)", s, strings.Join(levelNames, ", "))
+}
+
+// TrainerEvent событие расчета, которое получают подписчики EventBus
+type TrainerEvent interface {
+	Kind() string    // Тип события: pattern_detected, bet_decided, ...
+	Level() Level    // Важность события
+	Message() string // Описание для консоли
+}
+
+// PatternDetected на событии сработал паттерн
+type PatternDetected struct {
+	Strategy    string `json:"strategy,omitempty"`
+	EventNumber int    `json:"event"`
+	ID          string `json:"id"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+func (e PatternDetected) Kind() string { return "pattern_detected" }
+func (e PatternDetected) Level() Level { return LevelWarn }
+func (e PatternDetected) Message() string {
+	return fmt.Sprintf("⚠️ Событие номер %d: обнаружен паттерн %s [%s] - %s",
+		e.EventNumber, e.ID, e.Severity, e.Description)
+}
+
+// BetDecided стратегия приняла решение о ставках (после лимитов)
+type BetDecided struct {
+	Strategy    string     `json:"strategy"`
+	EventNumber int        `json:"event"`
+	Odds        PerOutcome `json:"odds"`
+	Bets        PerOutcome `json:"bets"`
+	Losses      PerOutcome `json:"losses"`
+	Total       float64    `json:"total"`
+	Reaction    string     `json:"reaction,omitempty"`
+	Limit       string     `json:"limit,omitempty"`
+}
+
+func (e BetDecided) Kind() string { return "bet_decided" }
+func (e BetDecided) Level() Level { return LevelDebug }
+func (e BetDecided) Message() string {
+	return fmt.Sprintf("Событие %d: решение %s, ставки: F=%.0f X=%.0f L=%.0f, убытки: F=%.0f X=%.0f L=%.0f",
+		e.EventNumber, e.Strategy, e.Bets.F, e.Bets.X, e.Bets.L, e.Losses.F, e.Losses.X, e.Losses.L)
+}
+
+// Settled событие рассчитано
+type Settled struct {
+	Strategy    string     `json:"strategy"`
+	EventNumber int        `json:"event"`
+	Result      string     `json:"result"`
+	Bets        PerOutcome `json:"bets"`
+	Losses      PerOutcome `json:"losses"`
+	Streaks     PerOutcome `json:"streaks"`
+	Total       float64    `json:"total"`
+}
+
+func (e Settled) Kind() string { return "settled" }
+func (e Settled) Level() Level { return LevelInfo }
+func (e Settled) Message() string {
+	return fmt.Sprintf("Событие %d: %s, Ставки: F=%.0f X=%.0f L=%.0f, Total=%.0f",
+		e.EventNumber, e.Result, e.Bets.F, e.Bets.X, e.Bets.L, e.Total)
+}
+
+// LossWrittenOff часть убытка списана из итога реакцией на паттерн или лимитом
+type LossWrittenOff struct {
+	Strategy    string  `json:"strategy"`
+	EventNumber int     `json:"event"`
+	Amount      float64 `json:"amount"`
+	Reason      string  `json:"reason"` // Реакция ("RED:writeoff(0.5)") или лимит (writeoff)
+}
+
+func (e LossWrittenOff) Kind() string { return "loss_written_off" }
+func (e LossWrittenOff) Level() Level { return LevelInfo }
+func (e LossWrittenOff) Message() string {
+	return fmt.Sprintf("✂️ Событие %d: списано из итога %.0f (%s)", e.EventNumber, e.Amount, e.Reason)
+}
+
+// CapHit ставки стратегии уперлись в лимит банка или ставок
+type CapHit struct {
+	Strategy    string     `json:"strategy"`
+	EventNumber int        `json:"event"`
+	Limit       string     `json:"limit"` // stop, clamp, writeoff или ruin
+	Planned     PerOutcome `json:"planned"`
+	Bets        PerOutcome `json:"bets"`
+}
+
+func (e CapHit) Kind() string { return "cap_hit" }
+func (e CapHit) Level() Level { return LevelWarn }
+func (e CapHit) Message() string {
+	return fmt.Sprintf("⛔ Событие %d: лимит ставок (%s), план F=%.0f X=%.0f L=%.0f, ставки F=%.0f X=%.0f L=%.0f",
+		e.EventNumber, e.Limit, e.Planned.F, e.Planned.X, e.Planned.L, e.Bets.F, e.Bets.X, e.Bets.L)
+}
+
+// DebugMessage отладочная строка расчета
+type DebugMessage struct {
+	Text string `json:"text"`
+}
+
+func (e DebugMessage) Kind() string    { return "debug" }
+func (e DebugMessage) Level() Level    { return LevelDebug }
+func (e DebugMessage) Message() string { return "DEBUG: " + e.Text }
+
+// Sink получатель событий тренажера
+type Sink interface {
+	Handle(event TrainerEvent)
+}
+
+// EventBus рассылает события подписчикам с учетом их минимального уровня.
+// Нулевой *EventBus ничего не делает, поэтому библиотека по умолчанию молчит.
+type EventBus struct {
+	subscribers []subscriber
+}
+
+type subscriber struct {
+	sink     Sink
+	minLevel Level
+}
+
+// NewEventBus создает шину без подписчиков
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe подписывает sink на события уровня minLevel и выше
+func (b *EventBus) Subscribe(sink Sink, minLevel Level) {
+	b.subscribers = append(b.subscribers, subscriber{sink: sink, minLevel: minLevel})
+}
+
+// Enabled сообщает, получит ли событие уровня level хотя бы один подписчик
+func (b *EventBus) Enabled(level Level) bool {
+	if b == nil {
+		return false
+	}
+	for _, s := range b.subscribers {
+		if level >= s.minLevel {
+			return true
+		}
+	}
+	return false
+}
+
+// Emit отправляет событие подписчикам
+func (b *EventBus) Emit(event TrainerEvent) {
+	if b == nil {
+		return
+	}
+	for _, s := range b.subscribers {
+		if event.Level() >= s.minLevel {
+			s.sink.Handle(event)
+		}
+	}
+}
+
+// Debugf отправляет отладочную строку; строка форматируется, только если
+// ее кто-то получит
+func (b *EventBus) Debugf(format string, args ...interface{}) {
+	if !b.Enabled(LevelDebug) {
+		return
+	}
+	b.Emit(DebugMessage{Text: fmt.Sprintf(format, args...)})
+}
@@ -0,0 +1,239 @@
+package trainer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/holygun/go-trainer/common"
+)
+
+// FootballDataOptions параметры импорта исторических матчей в формате
+// football-data.co.uk (Div, Date, HomeTeam, AwayTeam, FTR, B365H/B365D/B365A, ...)
+type FootballDataOptions struct {
+	Bookmaker string    // Префикс колонок коэффициентов: B365, PS, WH, Avg, Max, ... (пусто - B365)
+	Leagues   []string  // Лиги (колонка Div или League); пусто - все
+	Teams     []string  // Команды, игравшие дома или в гостях; пусто - все
+	From      time.Time // Первая дата (нулевая - без ограничения)
+	To        time.Time // Последняя дата включительно (нулевая - без ограничения)
+}
+
+// FootballDataImport результат импорта
+type FootballDataImport struct {
+	Events  []common.Event // События от старых к новым
+	Skipped int            // Матчи после фильтров без результата или без коэффициентов букмекера
+}
+
+// DefaultFootballDataBookmaker букмекер по умолчанию
+const DefaultFootballDataBookmaker = "B365"
+
+// footballDataAliases префиксы колонок средних и максимальных коэффициентов
+// в новых (Avg, Max) и старых (BbAv, BbMx) файлах
+var footballDataAliases = map[string][]string{
+	"avg": {"Avg", "BbAv"},
+	"max": {"Max", "BbMx"},
+}
+
+// footballDataResults результат матча (H/D/A) в событие тренажера
+var footballDataResults = map[string]string{"H": "F", "D": "X", "A": "L"}
+
+var footballDataDateLayouts = []string{"02/01/2006", "02/01/06", "2006-01-02"}
+
+// ImportFootballData читает матчи из CSV файлов football-data, фильтрует их и
+// переводит в события: победа хозяев - F, ничья - X, победа гостей - L.
+// Матчи всех файлов упорядочиваются по дате.
+func ImportFootballData(filenames []string, opts FootballDataOptions) (FootballDataImport, error) {
+	if opts.Bookmaker == "" {
+		opts.Bookmaker = DefaultFootballDataBookmaker
+	}
+
+	type dated struct {
+		date  time.Time
+		event common.Event
+	}
+	var matches []dated
+	result := FootballDataImport{}
+
+	for _, filename := range filenames {
+		file, err := os.Open(filename)
+		if err != nil {
+			return result, err
+		}
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+
+		rows, err := footballDataRows(reader, filename, opts)
+		file.Close()
+		if err != nil {
+			return result, err
+		}
+		for _, row := range rows {
+			if row.skipped {
+				result.Skipped++
+				continue
+			}
+			matches = append(matches, dated{date: row.date, event: row.event})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].date.Before(matches[j].date)
+	})
+	for _, match := range matches {
+		result.Events = append(result.Events, match.event)
+	}
+	return result, nil
+}
+
+// footballDataRow матч, прошедший фильтры
+type footballDataRow struct {
+	date    time.Time
+	event   common.Event
+	skipped bool // Нет результата или коэффициентов выбранного букмекера
+}
+
+// footballDataRows читает матчи одного файла
+func footballDataRows(reader *csv.Reader, filename string, opts FootballDataOptions) ([]footballDataRow, error) {
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		columns[strings.ToLower(name)] = i
+	}
+	column := func(names ...string) int {
+		for _, name := range names {
+			if i, ok := columns[strings.ToLower(name)]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+
+	resultColumn := column("FTR", "Res")
+	dateColumn := column("Date")
+	homeColumn := column("HomeTeam", "Home", "HT")
+	awayColumn := column("AwayTeam", "Away", "AT")
+	leagueColumn := column("Div", "League")
+	if resultColumn < 0 || dateColumn < 0 || homeColumn < 0 || awayColumn < 0 {
+		return nil, fmt.Errorf("%s: expected Date, HomeTeam, AwayTeam and FTR columns", filename)
+	}
+
+	prefixes := footballDataAliases[strings.ToLower(opts.Bookmaker)]
+	if prefixes == nil {
+		prefixes = []string{opts.Bookmaker}
+	}
+	oddsColumns := [3]int{-1, -1, -1}
+	for _, prefix := range prefixes {
+		if column(prefix+"H") >= 0 && column(prefix+"D") >= 0 && column(prefix+"A") >= 0 {
+			oddsColumns = [3]int{column(prefix + "H"), column(prefix + "D"), column(prefix + "A")}
+			break
+		}
+	}
+	if oddsColumns[0] < 0 {
+		return nil, fmt.Errorf("%s: no odds columns for bookmaker %s (expected %sH, %sD, %sA)",
+			filename, opts.Bookmaker, prefixes[0], prefixes[0], prefixes[0])
+	}
+
+	var rows []footballDataRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		line, _ := reader.FieldPos(0)
+		cell := func(i int) string {
+			if i < 0 || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		// Пустые строки в конце файлов football-data
+		if cell(dateColumn) == "" && cell(homeColumn) == "" {
+			continue
+		}
+
+		date, err := parseFootballDataDate(cell(dateColumn))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", filename, line, err)
+		}
+		league := cell(leagueColumn)
+		home, away := cell(homeColumn), cell(awayColumn)
+		if (!opts.From.IsZero() && date.Before(opts.From)) || (!opts.To.IsZero() && date.After(opts.To)) ||
+			!matchesAny(opts.Leagues, league) || (!matchesAny(opts.Teams, home) && !matchesAny(opts.Teams, away)) {
+			continue
+		}
+
+		row := footballDataRow{date: date}
+		outcome, ok := footballDataResults[cell(resultColumn)]
+		var odds [3]float64
+		for i, j := range oddsColumns {
+			odds[i], err = strconv.ParseFloat(cell(j), 64)
+			if err != nil || odds[i] <= 1 {
+				ok = false
+			}
+		}
+		if !ok {
+			row.skipped = true
+			rows = append(rows, row)
+			continue
+		}
+
+		row.event = common.Event{
+			Result: outcome,
+			OddF:   odds[0],
+			OddX:   odds[1],
+			OddL:   odds[2],
+			Match: common.Match{
+				Date:       date.Format("2006-01-02"),
+				Home:       home,
+				Away:       away,
+				League:     league,
+				Sport:      "football",
+				BookmakerF: opts.Bookmaker,
+				BookmakerX: opts.Bookmaker,
+				BookmakerL: opts.Bookmaker,
+			},
+		}
+		rows = append(rows, row)
+	}
+}
+
+// parseFootballDataDate разбирает дату в форматах dd/mm/yyyy, dd/mm/yy и yyyy-mm-dd
+func parseFootballDataDate(value string) (time.Time, error) {
+	for _, layout := range footballDataDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// matchesAny сообщает, совпадает ли value (без учета регистра) с одним из
+// значений фильтра; пустой фильтр пропускает все
+func matchesAny(filter []string, value string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, item := range filter {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
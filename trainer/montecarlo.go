@@ -0,0 +1,283 @@
+package trainer
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MonteCarloOptions параметры симуляции Монте-Карло
+type MonteCarloOptions struct {
+	Runs     int     // Количество независимых последовательностей
+	Events   int     // Количество событий в последовательности
+	Workers  int     // Количество параллельных обработчиков (0 - по числу CPU)
+	Seed     int64   // Базовый seed: последовательность i использует Seed+i
+	Exposure float64 // Порог суммарной ставки на событие для оценки вероятности превышения
+}
+
+// MonteCarloRun результат одной последовательности
+type MonteCarloRun struct {
+	Seed       int64
+	FinalTotal float64
+	PeakBet    float64 // Максимальная одиночная ставка
+	PeakStake  float64 // Максимальная сумма ставок betF+betX+betL на событие
+	PeakLosses PerOutcome
+	Patterns   map[string]int // Количество событий с каждым паттерном
+	StoppedAt  int            // Событие, на котором расчет остановлен лимитом или разорением (0 - нет)
+	Ruined     bool           // Расчет остановлен из-за нехватки банка
+}
+
+// Distribution распределение величины по последовательностям
+type Distribution struct {
+	Mean float64
+	Min  float64
+	P5   float64
+	P50  float64
+	P95  float64
+	P99  float64
+	Max  float64
+}
+
+// MonteCarloReport сводный результат симуляции
+type MonteCarloReport struct {
+	Strategy            string
+	Options             MonteCarloOptions
+	Runs                []MonteCarloRun
+	FinalTotal          Distribution
+	PeakBet             Distribution
+	PeakStake           Distribution
+	PeakLosses          map[string]Distribution
+	PatternEvents       map[string]int // Всего событий с паттерном
+	PatternRuns         map[string]int // Последовательностей, где паттерн встретился
+	ExposureProbability float64        // Доля последовательностей с PeakStake > Exposure
+	RuinProbability     float64        // Доля разорившихся последовательностей
+	StopProbability     float64        // Доля последовательностей, остановленных лимитом ставок
+}
+
+// ImpliedProbabilities возвращает вероятности исходов по коэффициентам без маржи
+func ImpliedProbabilities(odds Odds) PerOutcome {
+	f, x, l := 1/odds.OddF, 1/odds.OddX, 1/odds.OddL
+	margin := f + x + l
+	return PerOutcome{F: f / margin, X: x / margin, L: l / margin}
+}
+
+// drawOutcome разыгрывает исход события по вероятностям из коэффициентов
+func drawOutcome(rng *rand.Rand, odds Odds) string {
+	p := ImpliedProbabilities(odds)
+	r := rng.Float64()
+	if r < p.F {
+		return "F"
+	}
+	if r < p.F+p.X {
+		return "X"
+	}
+	return "L"
+}
+
+// RunMonteCarlo прогоняет стратегию по opts.Runs случайным последовательностям.
+// Каждая последовательность зависит только от своего seed, поэтому результат
+// не зависит от количества обработчиков.
+func RunMonteCarlo(strategy Strategy, flags Flags, opts MonteCarloOptions) MonteCarloReport {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	runs := make([]MonteCarloRun, opts.Runs)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				runs[i] = runSequence(strategy, flags, opts.Events, opts.Seed+int64(i))
+			}
+		}()
+	}
+	for i := 0; i < opts.Runs; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return summarizeMonteCarlo(strategy.Name(), opts, runs)
+}
+
+// randomSequence генерирует случайные коэффициенты по seed и разыгрывает по ним исходы
+func randomSequence(flags Flags, events int, seed int64) ([]string, []Odds) {
+	// Исходы разыгрываются отдельным генератором, чтобы не повторять
+	// поток случайных чисел генератора коэффициентов
+	provider := NewRandomOddsProvider(seed, flags)
+	rng := rand.New(rand.NewSource(^seed))
+
+	odds := make([]Odds, events)
+	eventsFromOldest := make([]string, events)
+	for i := range odds {
+		odds[i] = provider.Odds(i + 1)
+		eventsFromOldest[i] = drawOutcome(rng, odds[i])
+	}
+	return eventsFromOldest, odds
+}
+
+// runSequence генерирует одну последовательность и прогоняет по ней стратегию
+func runSequence(strategy Strategy, flags Flags, events int, seed int64) MonteCarloRun {
+	flags.Seed = seed
+	flags.Bus = nil
+
+	eventsFromOldest, odds := randomSequence(flags, events, seed)
+	provider := NewFixedOddsProvider(odds, NewRandomOddsProvider(seed, flags), flags)
+	records := Simulate(eventsFromOldest, provider, flags, strategy)
+
+	run := MonteCarloRun{Seed: seed, Patterns: PatternCounts(records)}
+	for _, record := range records {
+		run.PeakBet = math.Max(run.PeakBet, math.Max(record.BetF, math.Max(record.BetX, record.BetL)))
+		run.PeakStake = math.Max(run.PeakStake, record.BetF+record.BetX+record.BetL)
+		run.PeakLosses.F = math.Max(run.PeakLosses.F, record.LossF)
+		run.PeakLosses.X = math.Max(run.PeakLosses.X, record.LossX)
+		run.PeakLosses.L = math.Max(run.PeakLosses.L, record.LossL)
+	}
+	if len(records) > 0 {
+		last := records[len(records)-1]
+		run.FinalTotal = last.Total
+		if last.Stopped() {
+			run.StoppedAt = last.EventNumber
+			run.Ruined = last.Limit == LimitRuin
+		}
+	}
+
+	return run
+}
+
+// summarizeMonteCarlo строит распределения по результатам последовательностей
+func summarizeMonteCarlo(strategyName string, opts MonteCarloOptions, runs []MonteCarloRun) MonteCarloReport {
+	report := MonteCarloReport{
+		Strategy:      strategyName,
+		Options:       opts,
+		Runs:          runs,
+		PeakLosses:    map[string]Distribution{},
+		PatternEvents: map[string]int{},
+		PatternRuns:   map[string]int{},
+	}
+
+	values := func(get func(run MonteCarloRun) float64) []float64 {
+		result := make([]float64, len(runs))
+		for i, run := range runs {
+			result[i] = get(run)
+		}
+		return result
+	}
+
+	report.FinalTotal = NewDistribution(values(func(r MonteCarloRun) float64 { return r.FinalTotal }))
+	report.PeakBet = NewDistribution(values(func(r MonteCarloRun) float64 { return r.PeakBet }))
+	report.PeakStake = NewDistribution(values(func(r MonteCarloRun) float64 { return r.PeakStake }))
+	for _, outcome := range Outcomes {
+		outcome := outcome
+		report.PeakLosses[outcome] = NewDistribution(values(func(r MonteCarloRun) float64 { return r.PeakLosses.Get(outcome) }))
+	}
+
+	exceeded, ruined, stopped := 0, 0, 0
+	for _, run := range runs {
+		if run.Ruined {
+			ruined++
+		} else if run.StoppedAt > 0 {
+			stopped++
+		}
+		for pattern, count := range run.Patterns {
+			report.PatternEvents[pattern] += count
+			report.PatternRuns[pattern]++
+		}
+		if run.PeakStake > opts.Exposure {
+			exceeded++
+		}
+	}
+	if len(runs) > 0 {
+		report.ExposureProbability = float64(exceeded) / float64(len(runs))
+		report.RuinProbability = float64(ruined) / float64(len(runs))
+		report.StopProbability = float64(stopped) / float64(len(runs))
+	}
+
+	return report
+}
+
+// NewDistribution вычисляет среднее и перцентили значений
+func NewDistribution(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, value := range sorted {
+		sum += value
+	}
+
+	percentile := func(p float64) float64 {
+		index := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+		if index < 0 {
+			index = 0
+		}
+		return sorted[index]
+	}
+
+	return Distribution{
+		Mean: sum / float64(len(sorted)),
+		Min:  sorted[0],
+		P5:   percentile(5),
+		P50:  percentile(50),
+		P95:  percentile(95),
+		P99:  percentile(99),
+		Max:  sorted[len(sorted)-1],
+	}
+}
+
+// PrintMonteCarloReport выводит отчет симуляции Монте-Карло
+func PrintMonteCarloReport(report MonteCarloReport) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("                 🎲 МОНТЕ-КАРЛО: " + report.Strategy)
+	fmt.Println(strings.Repeat("=", 60))
+
+	fmt.Printf("\n   Последовательностей: %d, событий в каждой: %d, seed: %d\n",
+		report.Options.Runs, report.Options.Events, report.Options.Seed)
+
+	fmt.Printf("\n%-22s %12s %12s %12s %12s %12s %12s\n", "", "среднее", "p5", "p50", "p95", "p99", "максимум")
+	row := func(name string, d Distribution) {
+		fmt.Printf("%-22s %12.0f %12.0f %12.0f %12.0f %12.0f %12.0f\n", name, d.Mean, d.P5, d.P50, d.P95, d.P99, d.Max)
+	}
+	row("Итоговый результат", report.FinalTotal)
+	row("Макс. ставка", report.PeakBet)
+	row("Макс. сумма ставок", report.PeakStake)
+	row("Макс. убыток F", report.PeakLosses["F"])
+	row("Макс. убыток X", report.PeakLosses["X"])
+	row("Макс. убыток L", report.PeakLosses["L"])
+
+	fmt.Printf("\n⚠️ ПАТТЕРНЫ:\n")
+	if len(report.PatternEvents) == 0 {
+		fmt.Printf("   не обнаружены\n")
+	}
+	patternNames := make([]string, 0, len(report.PatternEvents))
+	for name := range report.PatternEvents {
+		patternNames = append(patternNames, name)
+	}
+	sort.Strings(patternNames)
+	totalEvents := float64(report.Options.Runs * report.Options.Events)
+	for _, name := range patternNames {
+		fmt.Printf("   %s: %.2f%% событий, в %.1f%% последовательностей\n", name,
+			float64(report.PatternEvents[name])/totalEvents*100,
+			float64(report.PatternRuns[name])/float64(report.Options.Runs)*100)
+	}
+
+	fmt.Printf("\n📈 Вероятность суммы ставок на событие > %.0f: %.2f%%\n",
+		report.Options.Exposure, report.ExposureProbability*100)
+	if report.RuinProbability > 0 || report.StopProbability > 0 {
+		fmt.Printf("💥 Вероятность разорения: %.2f%%\n", report.RuinProbability*100)
+		fmt.Printf("⛔ Вероятность остановки по лимиту ставок: %.2f%%\n", report.StopProbability*100)
+	}
+}
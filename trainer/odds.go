@@ -0,0 +1,185 @@
+package trainer
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/holygun/go-trainer/common"
+)
+
+// OddsProvider источник коэффициентов для симулятора
+type OddsProvider interface {
+	// Odds возвращает коэффициенты для события с номером eventNumber (с 1)
+	Odds(eventNumber int) Odds
+}
+
+// RandomOddsProvider генерирует случайные коэффициенты в диапазонах Config.OddsRanges.
+// Одинаковый seed дает одинаковую последовательность коэффициентов.
+type RandomOddsProvider struct {
+	seed  int64
+	rng   *rand.Rand
+	flags Flags
+}
+
+// NewRandomOddsProvider создает генератор коэффициентов с заданным seed
+func NewRandomOddsProvider(seed int64, flags Flags) *RandomOddsProvider {
+	return &RandomOddsProvider{
+		seed:  seed,
+		rng:   rand.New(rand.NewSource(seed)),
+		flags: flags,
+	}
+}
+
+// Seed возвращает seed генератора
+func (p *RandomOddsProvider) Seed() int64 {
+	return p.seed
+}
+
+// Odds генерирует коэффициенты с учетом ограничений
+func (p *RandomOddsProvider) Odds(eventNumber int) Odds {
+	maxAttempts := 1000
+	ranges := p.flags.EffectiveConfig().OddsRanges
+
+	for i := 0; i < maxAttempts; i++ {
+		oddF := ranges.OddF.Min + p.rng.Float64()*(ranges.OddF.Max-ranges.OddF.Min)
+		oddX := ranges.OddX.Min + p.rng.Float64()*(ranges.OddX.Max-ranges.OddX.Min)
+		oddL := ranges.OddL.Min + p.rng.Float64()*(ranges.OddL.Max-ranges.OddL.Min)
+
+		margin := 1/oddF + 1/oddX + 1/oddL
+
+		if margin >= ranges.MarginRange.Min && margin <= ranges.MarginRange.Max {
+			// Округляем до 2 знаков после запятой
+			oddF = math.Round(oddF*100) / 100
+			oddX = math.Round(oddX*100) / 100
+			oddL = math.Round(oddL*100) / 100
+
+			p.flags.Bus.Debugf("Event %d: Generated odds - F=%.2f, X=%.2f, L=%.2f", eventNumber, oddF, oddX, oddL)
+
+			if p.flags.Hockey {
+				return Odds{OddF: oddF, OddX: oddL, OddL: oddX}
+			}
+
+			return Odds{OddF: oddF, OddX: oddX, OddL: oddL}
+		}
+	}
+
+	p.flags.Bus.Debugf("[generateOdds] fallback to default odds")
+
+	if p.flags.Hockey {
+		return Odds{OddF: 2, OddX: 4, OddL: 3.5}
+	}
+
+	// Значения по умолчанию
+	return Odds{OddF: 2, OddX: 3.5, OddL: 4}
+}
+
+// MatchProvider провайдер коэффициентов, знающий метаданные матчей
+type MatchProvider interface {
+	// Match возвращает метаданные матча события eventNumber (с 1)
+	Match(eventNumber int) common.Match
+}
+
+// FixedOddsProvider отдает заранее известные коэффициенты по порядку событий,
+// а после их окончания обращается к fallback
+type FixedOddsProvider struct {
+	odds      []Odds
+	matches   []common.Match // Метаданные матчей (для .input файлов)
+	baselines []Odds         // Коэффициенты одного букмекера для сравнения с выбранной линией (nil - выбора не было)
+	fallback  OddsProvider
+	flags     Flags
+}
+
+// NewFixedOddsProvider создает провайдер заданных коэффициентов
+func NewFixedOddsProvider(odds []Odds, fallback OddsProvider, flags Flags) *FixedOddsProvider {
+	return &FixedOddsProvider{odds: odds, fallback: fallback, flags: flags}
+}
+
+// NewInputOddsProvider создает провайдер коэффициентов из событий .input файла
+func NewInputOddsProvider(events []common.Event, fallback OddsProvider, flags Flags) *FixedOddsProvider {
+	odds := make([]Odds, len(events))
+	matches := make([]common.Match, len(events))
+	var baselines []Odds
+	cfg := flags.EffectiveConfig().Bookmakers
+	for i, event := range events {
+		odds[i] = Odds{OddF: event.OddF, OddX: event.OddX, OddL: event.OddL}
+		matches[i] = event.Match
+		if len(event.Bookmakers) == 0 {
+			continue
+		}
+
+		// Коэффициенты нескольких букмекеров: ставка на каждый исход
+		// у букмекера с лучшей ценой
+		if baselines == nil {
+			baselines = make([]Odds, len(events))
+		}
+		choice := SelectLine(event.Bookmakers, cfg)
+		odds[i] = choice.Odds
+		for outcome, bookmaker := range choice.Bookmakers {
+			*matches[i].Field("bookmaker" + outcome) = bookmaker
+		}
+		baselines[i] = baselineOdds(event, cfg)
+	}
+	provider := NewFixedOddsProvider(odds, fallback, flags)
+	provider.matches = matches
+	provider.baselines = baselines
+	return provider
+}
+
+// NewReplayOddsProvider создает провайдер, повторяющий коэффициенты и
+// метаданные матчей существующего CSV файла (в порядке event_number)
+func NewReplayOddsProvider(filename string, fallback OddsProvider, flags Flags) (*FixedOddsProvider, error) {
+	records, err := ReadCSV(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].EventNumber < records[j].EventNumber
+	})
+
+	odds := make([]Odds, len(records))
+	matches := make([]common.Match, len(records))
+	for i, record := range records {
+		odds[i] = Odds{OddF: record.OddF, OddX: record.OddX, OddL: record.OddL}
+		matches[i] = record.Match
+	}
+	provider := NewFixedOddsProvider(odds, fallback, flags)
+	provider.matches = matches
+	return provider, nil
+}
+
+// Odds возвращает заданные коэффициенты события
+func (p *FixedOddsProvider) Odds(eventNumber int) Odds {
+	if eventNumber >= 1 && eventNumber <= len(p.odds) {
+		odds := p.odds[eventNumber-1]
+		p.flags.Bus.Debugf("Event %d: Using provided odds - F=%.2f, X=%.2f, L=%.2f", eventNumber, odds.OddF, odds.OddX, odds.OddL)
+		return odds
+	}
+	return p.fallback.Odds(eventNumber)
+}
+
+// Provided возвращает заданные коэффициенты события без обращения к fallback
+// (ok = false, если событие за пределами заданных)
+func (p *FixedOddsProvider) Provided(eventNumber int) (Odds, bool) {
+	if eventNumber >= 1 && eventNumber <= len(p.odds) {
+		return p.odds[eventNumber-1], true
+	}
+	return Odds{}, false
+}
+
+// Baseline возвращает коэффициенты одного букмекера для сравнения с выбранной линией
+func (p *FixedOddsProvider) Baseline(eventNumber int) (Odds, bool) {
+	if eventNumber >= 1 && eventNumber <= len(p.baselines) && p.baselines[eventNumber-1] != (Odds{}) {
+		return p.baselines[eventNumber-1], true
+	}
+	return Odds{}, false
+}
+
+// Match возвращает метаданные матча события (пустые, если их нет)
+func (p *FixedOddsProvider) Match(eventNumber int) common.Match {
+	if eventNumber >= 1 && eventNumber <= len(p.matches) {
+		return p.matches[eventNumber-1]
+	}
+	return common.Match{}
+}
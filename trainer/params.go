@@ -0,0 +1,149 @@
+package trainer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Типы параметров стратегий
+const (
+	ParamFloat = "float"
+	ParamInt   = "int"
+)
+
+// Param описание параметра стратегии
+type Param struct {
+	Name        string
+	Type        string // ParamFloat или ParamInt
+	Default     float64
+	Min         float64
+	Max         float64
+	Description string
+}
+
+// Params значения параметров стратегии по имени
+type Params map[string]float64
+
+// ParameterizedStrategy стратегия с объявленной схемой параметров
+type ParameterizedStrategy interface {
+	Strategy
+	Params() []Param
+}
+
+// StrategyParams возвращает схему параметров стратегии (пустую, если она не объявлена)
+func StrategyParams(strategy Strategy) []Param {
+	if ps, ok := strategy.(ParameterizedStrategy); ok {
+		return ps.Params()
+	}
+	return nil
+}
+
+// Validate проверяет значение параметра по схеме
+func (p Param) Validate(value float64) error {
+	if math.IsNaN(value) || value < p.Min || value > p.Max {
+		return fmt.Errorf("parameter %s: value %v is out of range [%v, %v]", p.Name, value, p.Min, p.Max)
+	}
+	if p.Type == ParamInt && value != math.Trunc(value) {
+		return fmt.Errorf("parameter %s: value %v must be an integer", p.Name, value)
+	}
+	return nil
+}
+
+// ValidateParams проверяет значения параметров стратегии по ее схеме
+func ValidateParams(strategy Strategy, values Params) error {
+	schema := map[string]Param{}
+	for _, param := range StrategyParams(strategy) {
+		schema[param.Name] = param
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		param, ok := schema[name]
+		if !ok {
+			return fmt.Errorf("strategy %s has no parameter %q", strategy.Name(), name)
+		}
+		if err := param.Validate(values[name]); err != nil {
+			return fmt.Errorf("strategy %s: %v", strategy.Name(), err)
+		}
+	}
+	return nil
+}
+
+// StrategyParams возвращает значения параметров стратегии: значения по
+// умолчанию, переопределенные из c.Strategies
+func (c Config) StrategyParams(strategy Strategy) Params {
+	values := Params{}
+	for _, param := range StrategyParams(strategy) {
+		values[param.Name] = param.Default
+	}
+	for name, value := range c.Strategies[strategy.Name()] {
+		values[name] = value
+	}
+	return values
+}
+
+// SetParam переопределяет параметр стратегии
+func (c *Config) SetParam(strategyName, name string, value float64) {
+	// Копируем карты, чтобы не менять их в других копиях Config
+	strategies := map[string]Params{}
+	for k, v := range c.Strategies {
+		strategies[k] = v
+	}
+	values := Params{}
+	for k, v := range strategies[strategyName] {
+		values[k] = v
+	}
+	values[name] = value
+	strategies[strategyName] = values
+	c.Strategies = strategies
+}
+
+// ParseParam парсит параметр вида "name=value"
+func ParseParam(s string) (string, float64, error) {
+	name, rawValue, ok := strings.Cut(s, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", 0, fmt.Errorf("invalid parameter %q: expected name=value", s)
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(rawValue), 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid parameter %q: %v", s, err)
+	}
+	return name, value, nil
+}
+
+// ParamsMeta возвращает значения параметров стратегии для строки
+// метаданных выходного файла в виде "strategy.name" -> "value"
+func ParamsMeta(strategy Strategy, cfg Config) map[string]string {
+	meta := map[string]string{}
+	for name, value := range cfg.StrategyParams(strategy) {
+		meta[strategy.Name()+"."+name] = strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	return meta
+}
+
+// RecordedParams возвращает параметры стратегии, записанные ParamsMeta в
+// метаданные выходного файла (ключи других стратегий пропускаются)
+func RecordedParams(strategy Strategy, meta map[string]string) (Params, error) {
+	values := Params{}
+	for key, rawValue := range meta {
+		name, ok := strings.CutPrefix(key, strategy.Name()+".")
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(rawValue, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recorded parameter %s=%q: %v", key, rawValue, err)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
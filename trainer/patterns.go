@@ -0,0 +1,316 @@
+package trainer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Уровни серьезности паттернов по возрастанию
+const (
+	SeverityLow    = "low"
+	SeverityMedium = "medium"
+	SeverityHigh   = "high"
+)
+
+var severityRank = map[string]int{SeverityLow: 1, SeverityMedium: 2, SeverityHigh: 3}
+
+// Метрики записи, доступные правилам паттернов
+var PatternMetrics = []string{"betF", "betX", "betL", "lossF", "lossX", "lossL"}
+
+// PatternContext данные, по которым правило проверяет паттерн
+type PatternContext struct {
+	Record       TrainerRecord // Рассчитанная запись события
+	RecentEvents []string      // Последние события от старых к новым, включая текущее
+	Config       Config
+}
+
+// PatternRule правило обнаружения паттерна
+type PatternRule interface {
+	ID() string
+	Severity() string // SeverityLow, SeverityMedium или SeverityHigh
+	Description(cfg Config) string
+	Match(ctx PatternContext) bool
+}
+
+// PatternMatch сработавший паттерн
+type PatternMatch struct {
+	ID       string
+	Severity string
+}
+
+// Реестр правил паттернов в порядке регистрации
+var (
+	patternRules = map[string]PatternRule{}
+	patternOrder []string
+)
+
+// RegisterPattern регистрирует правило паттерна. Правило с уже
+// зарегистрированным ID заменяет прежнее.
+func RegisterPattern(rule PatternRule) {
+	if _, exists := patternRules[rule.ID()]; !exists {
+		patternOrder = append(patternOrder, rule.ID())
+	}
+	patternRules[rule.ID()] = rule
+}
+
+// PatternRules возвращает правила для конфигурации: зарегистрированные правила,
+// включенные в cfg.Patterns.Builtin правила BuiltinSequenceRules и правила из
+// cfg.Patterns.Rules и cfg.Patterns.Sequences (правило конфигурации с тем же ID
+// заменяет зарегистрированное), от более серьезных к менее серьезным
+func PatternRules(cfg Config) []PatternRule {
+	rules := make([]PatternRule, 0, len(patternOrder)+len(cfg.Patterns.Rules)+len(cfg.Patterns.Sequences))
+	index := map[string]int{}
+	for _, id := range patternOrder {
+		index[id] = len(rules)
+		rules = append(rules, patternRules[id])
+	}
+	configured := []PatternRule{}
+	for _, id := range cfg.Patterns.Builtin {
+		if rule, ok := builtinSequenceRule(id); ok {
+			configured = append(configured, rule)
+		}
+	}
+	for i := range cfg.Patterns.Rules {
+		configured = append(configured, &cfg.Patterns.Rules[i])
+	}
+	for i := range cfg.Patterns.Sequences {
+		configured = append(configured, &cfg.Patterns.Sequences[i])
+	}
+	for _, rule := range configured {
+		if i, exists := index[rule.ID()]; exists {
+			rules[i] = rule
+			continue
+		}
+		index[rule.ID()] = len(rules)
+		rules = append(rules, rule)
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return severityRank[rules[i].Severity()] > severityRank[rules[j].Severity()]
+	})
+	return rules
+}
+
+// ThresholdRule паттерн по превышению порогов метриками записи. Паттерн
+// срабатывает, если выполнено хотя бы одно из условий.
+type ThresholdRule struct {
+	Name       string               `json:"id" yaml:"id"`
+	Level      string               `json:"severity" yaml:"severity"`
+	Text       string               `json:"description,omitempty" yaml:"description,omitempty"` // Пусто - описание строится по условиям
+	Conditions []ThresholdCondition `json:"conditions" yaml:"conditions"`
+}
+
+// ThresholdCondition условие: не меньше Count метрик из Metrics больше порога Above
+type ThresholdCondition struct {
+	Metrics []string `json:"metrics,omitempty" yaml:"metrics,omitempty"` // Пусто - все PatternMetrics
+	Above   string   `json:"above" yaml:"above"`                         // "small", "big" (patterns.small_mult/big_mult) или число базовых ставок
+	Count   int      `json:"count" yaml:"count"`
+}
+
+func (r *ThresholdRule) ID() string       { return r.Name }
+func (r *ThresholdRule) Severity() string { return r.Level }
+
+// Description описывает правило; пороги считаются по конфигурации,
+// поэтому описание всегда совпадает с проверкой
+func (r *ThresholdRule) Description(cfg Config) string {
+	if r.Text != "" {
+		return r.Text
+	}
+	parts := make([]string, len(r.Conditions))
+	for i, condition := range r.Conditions {
+		threshold, _ := condition.threshold(cfg)
+		metrics := "метрик"
+		if len(condition.Metrics) > 0 {
+			metrics = strings.Join(condition.Metrics, "/")
+		}
+		parts[i] = fmt.Sprintf("%d+ %s > %.0f", condition.Count, metrics, threshold)
+	}
+	return strings.Join(parts, " или ")
+}
+
+// Match проверяет условия правила
+func (r *ThresholdRule) Match(ctx PatternContext) bool {
+	for _, condition := range r.Conditions {
+		threshold, err := condition.threshold(ctx.Config)
+		if err != nil {
+			continue
+		}
+		metrics := condition.Metrics
+		if len(metrics) == 0 {
+			metrics = PatternMetrics
+		}
+		count := 0
+		for _, metric := range metrics {
+			if value, _ := recordMetric(ctx.Record, metric); value > threshold {
+				count++
+			}
+		}
+		if count >= condition.Count {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate проверяет правило из конфигурации
+func (r *ThresholdRule) Validate() error {
+	if r.Name == "" || strings.Contains(r.Name, "_") {
+		return fmt.Errorf("pattern rule %q: id must be non-empty and must not contain '_'", r.Name)
+	}
+	if _, ok := severityRank[r.Level]; !ok {
+		return fmt.Errorf("pattern rule %s: unknown severity %q (expected %s, %s or %s)", r.Name, r.Level, SeverityLow, SeverityMedium, SeverityHigh)
+	}
+	if len(r.Conditions) == 0 {
+		return fmt.Errorf("pattern rule %s: no conditions", r.Name)
+	}
+	for _, condition := range r.Conditions {
+		if _, err := condition.threshold(DefaultConfig()); err != nil {
+			return fmt.Errorf("pattern rule %s: %v", r.Name, err)
+		}
+		if condition.Count < 1 {
+			return fmt.Errorf("pattern rule %s: count must be at least 1, got %d", r.Name, condition.Count)
+		}
+		for _, metric := range condition.Metrics {
+			if _, ok := recordMetric(TrainerRecord{}, metric); !ok {
+				return fmt.Errorf("pattern rule %s: unknown metric %q (known: %s)", r.Name, metric, strings.Join(PatternMetrics, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// threshold возвращает порог условия в единицах ставок
+func (c ThresholdCondition) threshold(cfg Config) (float64, error) {
+	switch c.Above {
+	case "small":
+		return cfg.Patterns.SmallMult * cfg.DefaultBetF, nil
+	case "big":
+		return cfg.Patterns.BigMult * cfg.DefaultBetF, nil
+	}
+	mult, err := strconv.ParseFloat(c.Above, 64)
+	if err != nil || mult <= 0 {
+		return 0, fmt.Errorf("invalid threshold %q: expected small, big or a positive number of base bets", c.Above)
+	}
+	return mult * cfg.DefaultBetF, nil
+}
+
+// recordMetric возвращает метрику записи по имени
+func recordMetric(record TrainerRecord, name string) (float64, bool) {
+	switch name {
+	case "betF":
+		return record.BetF, true
+	case "betX":
+		return record.BetX, true
+	case "betL":
+		return record.BetL, true
+	case "lossF":
+		return record.LossF, true
+	case "lossX":
+		return record.LossX, true
+	case "lossL":
+		return record.LossL, true
+	}
+	return 0, false
+}
+
+// PatternDetector детектор паттернов
+type PatternDetector struct {
+	recentEvents []string
+	windowSize   int
+	config       Config
+	rules        []PatternRule
+	bus          *EventBus
+	strategy     string // Стратегия, для которой детектор проверяет записи
+}
+
+// windowedRule правило, которому нужны последние события
+type windowedRule interface {
+	Window() int // Количество последних событий, которое читает правило
+}
+
+// NewPatternDetector создает новый детектор с правилами PatternRules(cfg).
+// Окно последних событий - не меньше 10 и не меньше окна любого правила.
+func NewPatternDetector(cfg Config) *PatternDetector {
+	rules := PatternRules(cfg)
+	windowSize := 10
+	for _, rule := range rules {
+		if windowed, ok := rule.(windowedRule); ok && windowed.Window() > windowSize {
+			windowSize = windowed.Window()
+		}
+	}
+	return &PatternDetector{
+		recentEvents: make([]string, 0),
+		windowSize:   windowSize,
+		config:       cfg,
+		rules:        rules,
+	}
+}
+
+// AddEvent добавляет событие и проверяет все правила паттернов.
+// Сработавшие паттерны возвращаются от более серьезных к менее серьезным.
+func (pd *PatternDetector) AddEvent(event string, eventNumber int, record TrainerRecord) []PatternMatch {
+	pd.recentEvents = append(pd.recentEvents, event)
+	if len(pd.recentEvents) > pd.windowSize {
+		pd.recentEvents = pd.recentEvents[1:]
+	}
+
+	ctx := PatternContext{Record: record, RecentEvents: pd.recentEvents, Config: pd.config}
+	matches := []PatternMatch{}
+	for _, rule := range pd.rules {
+		if !rule.Match(ctx) {
+			continue
+		}
+		matches = append(matches, PatternMatch{ID: rule.ID(), Severity: rule.Severity()})
+		if pd.bus.Enabled(LevelWarn) {
+			pd.bus.Emit(PatternDetected{Strategy: pd.strategy, EventNumber: eventNumber,
+				ID: rule.ID(), Severity: rule.Severity(), Description: rule.Description(pd.config)})
+		}
+	}
+
+	return matches
+}
+
+// setPatterns записывает сработавшие паттерны в запись
+func (r *TrainerRecord) setPatterns(matches []PatternMatch) {
+	if len(matches) == 0 {
+		return
+	}
+	ids := make([]string, len(matches))
+	for i, match := range matches {
+		ids[i] = match.ID
+		if severityRank[match.Severity] > severityRank[r.Severity] {
+			r.Severity = match.Severity
+		}
+	}
+	r.Pattern = strings.Join(ids, "_")
+	r.Patterns = matches
+}
+
+// HasPattern сообщает, сработал ли на событии паттерн id
+func (r TrainerRecord) HasPattern(id string) bool {
+	if r.Pattern == "" {
+		return false
+	}
+	for _, pattern := range strings.Split(r.Pattern, "_") {
+		if pattern == id {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	// Встроенные паттерны; пороги small и big задаются patterns.small_mult и patterns.big_mult
+	RegisterPattern(&ThresholdRule{Name: "RED", Level: SeverityHigh, Conditions: []ThresholdCondition{
+		{Above: "big", Count: 3},
+	}})
+	RegisterPattern(&ThresholdRule{Name: "YELLOW", Level: SeverityMedium, Conditions: []ThresholdCondition{
+		{Above: "small", Count: 2},
+		{Above: "big", Count: 1},
+	}})
+	RegisterPattern(&ThresholdRule{Name: "GREEN", Level: SeverityLow, Conditions: []ThresholdCondition{
+		{Above: "small", Count: 1},
+	}})
+}
@@ -0,0 +1,272 @@
+package trainer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Reaction политика реакции на паттерн предыдущего события с параметрами
+type Reaction struct {
+	Policy   string  `json:"policy" yaml:"policy"`                         // Имя политики из реестра (writeoff, cap, pause, move)
+	Fraction float64 `json:"fraction,omitempty" yaml:"fraction,omitempty"` // writeoff: доля списываемого убытка
+	Max      float64 `json:"max,omitempty" yaml:"max,omitempty"`           // cap: максимум переносимого убытка в базовых ставках
+	Events   int     `json:"events,omitempty" yaml:"events,omitempty"`     // pause: количество событий без ставок
+	To       string  `json:"to,omitempty" yaml:"to,omitempty"`             // move: исход, на который переносится убыток
+}
+
+// String описание реакции для записи, например "writeoff(0.5)"
+func (r Reaction) String() string {
+	switch r.Policy {
+	case "writeoff":
+		return r.Policy + "(" + strconv.FormatFloat(r.Fraction, 'g', -1, 64) + ")"
+	case "cap":
+		return r.Policy + "(" + strconv.FormatFloat(r.Max, 'g', -1, 64) + ")"
+	case "pause":
+		return r.Policy + "(" + strconv.Itoa(r.Events) + ")"
+	case "move":
+		return r.Policy + "(" + r.To + ")"
+	}
+	return r.Policy
+}
+
+// ReactionState убыток, который стратегия собирается перенести на ставки,
+// и решения политик о нем
+type ReactionState struct {
+	RealLoss float64 // Убыток сверх базовых ставок, который стратегия распределит по исходам
+	Total    float64 // Итог до расчета события
+	Target   string  // Исход, на который перенести весь убыток ("" - распределение стратегии)
+	Pause    int     // Количество событий без ставок, начиная с текущего
+	Applied  string  // Примененная реакция "ПАТТЕРН:политика(параметры)"
+}
+
+// ReactionPolicy именованная политика реакции на паттерн
+type ReactionPolicy interface {
+	Name() string
+	Description() string
+	Validate(reaction Reaction) error
+	React(state *ReactionState, reaction Reaction, cfg Config)
+}
+
+var reactionPolicies = map[string]ReactionPolicy{}
+
+// RegisterReaction регистрирует политику реакции
+func RegisterReaction(policy ReactionPolicy) {
+	reactionPolicies[policy.Name()] = policy
+}
+
+// ReactionNames возвращает имена зарегистрированных политик в алфавитном порядке
+func ReactionNames() []string {
+	names := make([]string, 0, len(reactionPolicies))
+	for name := range reactionPolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate проверяет реакцию по ее политике
+func (r Reaction) Validate() error {
+	policy, ok := reactionPolicies[r.Policy]
+	if !ok {
+		return fmt.Errorf("unknown reaction policy %q (known: %s)", r.Policy, strings.Join(ReactionNames(), ", "))
+	}
+	return policy.Validate(r)
+}
+
+// ReactiveStrategy стратегия с реакциями на паттерны по умолчанию
+type ReactiveStrategy interface {
+	Strategy
+	// DefaultReactions возвращает реакции по ID паттерна или уровню серьезности
+	DefaultReactions(params Params) map[string]Reaction
+}
+
+// reactionLayers возвращает реакции стратегии по убыванию приоритета:
+// из c.Reactions, затем DefaultReactions стратегии
+func (c Config) reactionLayers(strategy Strategy) []map[string]Reaction {
+	layers := []map[string]Reaction{c.Reactions[strategy.Name()]}
+	if rs, ok := strategy.(ReactiveStrategy); ok {
+		layers = append(layers, rs.DefaultReactions(c.StrategyParams(strategy)))
+	}
+	return layers
+}
+
+// React применяет к убытку реакцию стратегии на паттерны предыдущего события.
+// Паттерны просматриваются от более серьезных к менее серьезным; применяется
+// реакция первого паттерна, для которого она задана (реакция "none" на него
+// отменяет реакции на менее серьезные паттерны). Реакция из конфигурации по ID
+// или серьезности паттерна заменяет реакцию стратегии по умолчанию.
+// Пауза не продлевается паттерном события, на котором ставок не было:
+// убытки во время паузы не меняются, и паттерн повторялся бы бесконечно.
+func React(strategy Strategy, previous *TrainerRecord, realLoss, total float64, cfg Config) ReactionState {
+	state := ReactionState{RealLoss: realLoss, Total: total}
+	if previous.Pattern == "" {
+		return state
+	}
+
+	severities := map[string]string{}
+	for _, rule := range PatternRules(cfg) {
+		severities[rule.ID()] = rule.Severity()
+	}
+
+	layers := cfg.reactionLayers(strategy)
+	idle := previous.BetF == 0 && previous.BetX == 0 && previous.BetL == 0
+	for _, id := range strings.Split(previous.Pattern, "_") {
+		reaction, ok := lookupReaction(layers, id, severities[id])
+		if !ok {
+			continue
+		}
+		policy, ok := reactionPolicies[reaction.Policy]
+		if !ok || reaction.Policy == "none" {
+			break
+		}
+		if reaction.Policy == "pause" && idle {
+			continue
+		}
+		policy.React(&state, reaction, cfg)
+		state.Applied = id + ":" + reaction.String()
+		break
+	}
+	return state
+}
+
+// lookupReaction ищет реакцию на паттерн по ID, затем по серьезности
+// в первом слое, где она задана
+func lookupReaction(layers []map[string]Reaction, id, severity string) (Reaction, bool) {
+	for _, layer := range layers {
+		if reaction, ok := layer[id]; ok {
+			return reaction, true
+		}
+		if reaction, ok := layer[severity]; ok {
+			return reaction, true
+		}
+	}
+	return Reaction{}, false
+}
+
+// pausedDecision решение без ставок: убытки, серии и итог предыдущего события
+// переносятся без изменений (иначе выигравший исход обнулил бы серию, и
+// стратегия сбросила бы его убыток к базовой сумме без списания)
+func pausedDecision(strategyName string, previous *TrainerRecord, remaining int, reaction string, flags Flags) BetDecision {
+	losses := PerOutcome{F: previous.LossF, X: previous.LossX, L: previous.LossL}
+	decision := BetDecision{
+		Strategy: strategyName,
+		Losses:   losses,
+		Residual: losses,
+		Total:    previous.Total,
+		Paused:   remaining,
+		Skipped:  true,
+		Reaction: reaction,
+	}
+	trace := flags.NewTrace()
+	trace.Add("paused", float64(remaining))
+	decision.Trace = trace.Entries()
+	return decision
+}
+
+// decide принимает решение стратегии с учетом паузы, объявленной реакцией
+// на одном из прошлых событий
+func decide(strategy Strategy, previous *TrainerRecord, odds Odds, flags Flags) BetDecision {
+	if previous.Paused > 0 {
+		return pausedDecision(strategy.Name(), previous, previous.Paused-1, "", flags)
+	}
+	return strategy.Decide(previous, odds, flags)
+}
+
+// WriteOffPolicy списывает долю убытка из итога
+type WriteOffPolicy struct{}
+
+func (p *WriteOffPolicy) Name() string { return "writeoff" }
+func (p *WriteOffPolicy) Description() string {
+	return "Списать из итога долю fraction убытка, остальное перенести на ставки"
+}
+
+func (p *WriteOffPolicy) Validate(r Reaction) error {
+	if r.Fraction < 0 || r.Fraction > 1 {
+		return fmt.Errorf("writeoff: fraction must be in [0, 1], got %v", r.Fraction)
+	}
+	return nil
+}
+
+func (p *WriteOffPolicy) React(state *ReactionState, r Reaction, cfg Config) {
+	state.Total -= cfg.roundUp(state.RealLoss * r.Fraction)
+	state.RealLoss = cfg.roundUp(state.RealLoss * (1 - r.Fraction))
+}
+
+// CapPolicy ограничивает переносимый убыток, остаток списывается из итога
+type CapPolicy struct{}
+
+func (p *CapPolicy) Name() string { return "cap" }
+func (p *CapPolicy) Description() string {
+	return "Перенести на ставки не больше max базовых ставок убытка, остальное списать из итога"
+}
+
+func (p *CapPolicy) Validate(r Reaction) error {
+	if r.Max < 0 {
+		return fmt.Errorf("cap: max must be non-negative, got %v", r.Max)
+	}
+	return nil
+}
+
+func (p *CapPolicy) React(state *ReactionState, r Reaction, cfg Config) {
+	limit := r.Max * cfg.DefaultBetF
+	if state.RealLoss > limit {
+		state.Total -= state.RealLoss - limit
+		state.RealLoss = limit
+	}
+}
+
+// PausePolicy пропускает ставки на несколько событий
+type PausePolicy struct{}
+
+func (p *PausePolicy) Name() string { return "pause" }
+func (p *PausePolicy) Description() string {
+	return "Не ставить events событий, начиная с текущего; убытки переносятся без изменений"
+}
+
+func (p *PausePolicy) Validate(r Reaction) error {
+	if r.Events < 1 {
+		return fmt.Errorf("pause: events must be at least 1, got %d", r.Events)
+	}
+	return nil
+}
+
+func (p *PausePolicy) React(state *ReactionState, r Reaction, cfg Config) {
+	state.Pause = r.Events
+}
+
+// MovePolicy переносит весь убыток на один исход
+type MovePolicy struct{}
+
+func (p *MovePolicy) Name() string { return "move" }
+func (p *MovePolicy) Description() string {
+	return "Перенести весь убыток на исход to вместо распределения стратегии"
+}
+
+func (p *MovePolicy) Validate(r Reaction) error {
+	if r.To != "F" && r.To != "X" && r.To != "L" {
+		return fmt.Errorf("move: to must be F, X or L, got %q", r.To)
+	}
+	return nil
+}
+
+func (p *MovePolicy) React(state *ReactionState, r Reaction, cfg Config) {
+	state.Target = r.To
+}
+
+// NonePolicy отключает реакцию, заданную по умолчанию
+type NonePolicy struct{}
+
+func (p *NonePolicy) Name() string                                       { return "none" }
+func (p *NonePolicy) Description() string                                { return "Не реагировать" }
+func (p *NonePolicy) Validate(r Reaction) error                          { return nil }
+func (p *NonePolicy) React(state *ReactionState, r Reaction, cfg Config) {}
+
+func init() {
+	RegisterReaction(&WriteOffPolicy{})
+	RegisterReaction(&CapPolicy{})
+	RegisterReaction(&PausePolicy{})
+	RegisterReaction(&MovePolicy{})
+	RegisterReaction(&NonePolicy{})
+}
@@ -0,0 +1,232 @@
+package trainer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Виды спорта real-games файлов
+const (
+	SportFootball = "football"
+	SportHockey   = "hockey"
+)
+
+// RealGameMeta настройки расчета real-games файла из необязательного файла
+// <name>.meta (JSON или YAML) рядом с <name>.input
+type RealGameMeta struct {
+	Strategy   string   `json:"strategy,omitempty" yaml:"strategy,omitempty"`       // Стратегия (по умолчанию из имени файла)
+	Params     Params   `json:"params,omitempty" yaml:"params,omitempty"`           // Параметры стратегии поверх конфигурации
+	Sport      string   `json:"sport,omitempty" yaml:"sport,omitempty"`             // football или hockey (по умолчанию по тегу hockey)
+	DefaultBet float64  `json:"default_bet,omitempty" yaml:"default_bet,omitempty"` // Базовая ставка (по умолчанию из конфигурации)
+	Tags       []string `json:"tags,omitempty" yaml:"tags,omitempty"`               // Теги в дополнение к тегам имени файла
+	Notes      string   `json:"notes,omitempty" yaml:"notes,omitempty"`             // Заметки, в расчете не участвуют
+}
+
+// RealGameMetaPath возвращает путь к .meta файлу для .input файла
+func RealGameMetaPath(inputFile string) string {
+	return strings.TrimSuffix(inputFile, ".input") + ".meta"
+}
+
+// LoadRealGameMeta читает .meta файл рядом с .input файлом. Без .meta файла
+// возвращаются пустые настройки. JSON отличается от YAML по первой '{'.
+func LoadRealGameMeta(inputFile string) (RealGameMeta, error) {
+	var meta RealGameMeta
+	filename := RealGameMetaPath(inputFile)
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return meta, nil
+	}
+	if err != nil {
+		return meta, err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&meta)
+	} else if len(bytes.TrimSpace(data)) > 0 {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&meta)
+	}
+	if err == nil {
+		err = meta.Validate()
+	}
+	if err != nil {
+		return meta, fmt.Errorf("invalid meta %s: %v", filename, err)
+	}
+	return meta, nil
+}
+
+// Validate проверяет значения .meta файла
+func (m RealGameMeta) Validate() error {
+	if m.Sport != "" && m.Sport != SportFootball && m.Sport != SportHockey {
+		return fmt.Errorf("sport: expected %s or %s, got %q", SportFootball, SportHockey, m.Sport)
+	}
+	if m.DefaultBet < 0 {
+		return fmt.Errorf("default_bet must be positive, got %v", m.DefaultBet)
+	}
+	for _, tag := range m.Tags {
+		if err := ValidateTag(tag); err != nil {
+			return fmt.Errorf("tags: %v", err)
+		}
+	}
+	return nil
+}
+
+// RealGameSettings настройки расчета real-games файла
+type RealGameSettings struct {
+	Strategy Strategy
+	Hockey   bool
+	Config   Config
+}
+
+// Resolve применяет .meta к настройкам из имени файла: стратегия и вид спорта
+// из .meta сильнее имени файла, базовая ставка и параметры заменяют значения
+// конфигурации base
+func (m RealGameMeta) Resolve(strategyName string, hockey bool, base Config) (RealGameSettings, error) {
+	if m.Strategy != "" {
+		strategyName = m.Strategy
+	}
+	strategy, err := GetStrategy(strategyName)
+	if err != nil {
+		return RealGameSettings{}, err
+	}
+	if m.Sport != "" {
+		hockey = m.Sport == SportHockey
+	}
+
+	cfg := base
+	if m.DefaultBet > 0 {
+		cfg.DefaultBetF = m.DefaultBet
+	}
+	for name, value := range m.Params {
+		cfg.SetParam(strategy.Name(), name, value)
+	}
+	if err := cfg.Validate(); err != nil {
+		return RealGameSettings{}, err
+	}
+	return RealGameSettings{Strategy: strategy, Hockey: hockey, Config: cfg}, nil
+}
+
+// Ключи строки параметров .actual файла с отпечатком расчета
+const (
+	metaInputHash       = "input_hash"
+	metaStrategy        = "strategy"
+	metaStrategyVersion = "strategy_version"
+	metaConfigHash      = "config_hash"
+)
+
+// RealGameFingerprint отпечаток расчета .actual файла: по нему trainer real
+// решает, нужно ли пересчитать файл
+type RealGameFingerprint struct {
+	InputHash       string // Хеш содержимого .input файла
+	Strategy        string
+	StrategyVersion string
+	ConfigHash      string // Хеш настроек расчета файла (с .meta и -param), вида спорта и колонок trace.*
+}
+
+// NewRealGameFingerprint вычисляет отпечаток расчета .input файла с флагами
+// flags. В хеш настроек входит только то, что влияет на расчет файла:
+// параметры и реакции его стратегии, базовая ставка, округление, паттерны,
+// банк, вид спорта, колонки trace.* и выбор букмекера (если в файле есть
+// колонки oddF@name). Теги, диапазоны коэффициентов и настройки других
+// стратегий файл не пересчитывают.
+func NewRealGameFingerprint(inputFile string, strategy Strategy, flags Flags) (RealGameFingerprint, error) {
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return RealGameFingerprint{}, err
+	}
+	events, err := ReadInputFile(inputFile)
+	if err != nil {
+		return RealGameFingerprint{}, err
+	}
+	cfg := flags.EffectiveConfig()
+	var bookmakers *BookmakerConfig
+	for _, event := range events {
+		if len(event.Bookmakers) > 0 {
+			bookmakers = &cfg.Bookmakers
+			break
+		}
+	}
+	settings, err := json.Marshal(struct {
+		Params     Params
+		DefaultBet float64
+		RoundUp    float64
+		Patterns   PatternsConfig
+		Reactions  map[string]Reaction
+		Bankroll   BankrollConfig
+		Bookmakers *BookmakerConfig
+		Hockey     bool
+		Trace      bool
+	}{cfg.StrategyParams(strategy), cfg.DefaultBetF, cfg.RoundUp, cfg.Patterns, cfg.Reactions[strategy.Name()],
+		cfg.Bankroll, bookmakers, flags.Hockey, flags.Trace})
+	if err != nil {
+		return RealGameFingerprint{}, err
+	}
+	return RealGameFingerprint{
+		InputHash:       shortHash(data),
+		Strategy:        strategy.Name(),
+		StrategyVersion: StrategyVersion(strategy),
+		ConfigHash:      shortHash(settings),
+	}, nil
+}
+
+// shortHash первые 16 hex-символов SHA-256
+func shortHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// Meta возвращает отпечаток для строки параметров CSV (CSVOptions.Meta)
+func (f RealGameFingerprint) Meta() map[string]string {
+	return map[string]string{
+		metaInputHash:       f.InputHash,
+		metaStrategy:        f.Strategy,
+		metaStrategyVersion: f.StrategyVersion,
+		metaConfigHash:      f.ConfigHash,
+	}
+}
+
+// ReadRealGameFingerprint читает отпечаток из строки параметров .actual файла.
+// У файлов, записанных до появления отпечатка, поля хешей пустые.
+func ReadRealGameFingerprint(actualFile string) (RealGameFingerprint, error) {
+	meta, err := ReadCSVMeta(actualFile)
+	if err != nil {
+		return RealGameFingerprint{}, err
+	}
+	return RealGameFingerprint{
+		InputHash:       meta[metaInputHash],
+		Strategy:        meta[metaStrategy],
+		StrategyVersion: meta[metaStrategyVersion],
+		ConfigHash:      meta[metaConfigHash],
+	}, nil
+}
+
+// Changes перечисляет отличия отпечатка от сохраненного в .actual файле
+// (пустой список - пересчет не нужен)
+func (f RealGameFingerprint) Changes(stored RealGameFingerprint) []string {
+	if stored.InputHash == "" || stored.ConfigHash == "" {
+		return []string{"в .actual нет отпечатка расчета"}
+	}
+	var changes []string
+	if f.InputHash != stored.InputHash {
+		changes = append(changes, "изменен .input")
+	}
+	if f.Strategy != stored.Strategy {
+		changes = append(changes, fmt.Sprintf("стратегия %s -> %s", stored.Strategy, f.Strategy))
+	} else if f.StrategyVersion != stored.StrategyVersion {
+		changes = append(changes, fmt.Sprintf("версия стратегии %s -> %s", stored.StrategyVersion, f.StrategyVersion))
+	}
+	if f.ConfigHash != stored.ConfigHash {
+		changes = append(changes, "изменены настройки расчета")
+	}
+	return changes
+}
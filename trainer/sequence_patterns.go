@@ -0,0 +1,220 @@
+package trainer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Виды условий SequenceCondition
+const (
+	SequenceRun       = "run"       // Последние Length событий - из Events (например, серия не-F)
+	SequenceAlternate = "alternate" // Последние Length событий чередуются между исходами Events
+	SequenceCount     = "count"     // Не меньше Length из последних Window событий - из Events
+	SequenceStreak    = "streak"    // Счетчик записи Counter (uf, ux, ul) не меньше Length
+)
+
+// SequenceRule паттерн формы серии событий. Паттерн срабатывает, если
+// выполнено хотя бы одно из условий.
+type SequenceRule struct {
+	Name       string              `json:"id" yaml:"id"`
+	Level      string              `json:"severity" yaml:"severity"`
+	Text       string              `json:"description,omitempty" yaml:"description,omitempty"` // Пусто - описание строится по условиям
+	Conditions []SequenceCondition `json:"conditions" yaml:"conditions"`
+}
+
+// SequenceCondition условие на последние события или счетчики серий
+type SequenceCondition struct {
+	Kind    string `json:"kind" yaml:"kind"`                           // SequenceRun, SequenceAlternate, SequenceCount или SequenceStreak
+	Events  string `json:"events,omitempty" yaml:"events,omitempty"`   // Исходы, например "XL"
+	Length  int    `json:"length" yaml:"length"`                       // Длина серии, количество событий или порог счетчика
+	Window  int    `json:"window,omitempty" yaml:"window,omitempty"`   // Количество последних событий для SequenceCount
+	Counter string `json:"counter,omitempty" yaml:"counter,omitempty"` // uf, ux или ul для SequenceStreak
+}
+
+// BuiltinSequenceRules встроенные правила формы серий. По умолчанию они не
+// действуют (иначе изменилась бы колонка pattern сохраненных файлов, а через
+// реакции и ставки) и включаются по ID в patterns.builtin.
+var BuiltinSequenceRules = []SequenceRule{
+	{Name: "NOFRUN", Level: SeverityLow, Conditions: []SequenceCondition{
+		{Kind: SequenceRun, Events: "XL", Length: 5},
+	}},
+	{Name: "XLSWING", Level: SeverityLow, Conditions: []SequenceCondition{
+		{Kind: SequenceAlternate, Events: "XL", Length: 4},
+	}},
+	{Name: "LHEAVY", Level: SeverityLow, Conditions: []SequenceCondition{
+		{Kind: SequenceCount, Events: "L", Length: 5, Window: 10},
+	}},
+	{Name: "FDROUGHT", Level: SeverityMedium, Conditions: []SequenceCondition{
+		{Kind: SequenceStreak, Counter: "uf", Length: 8},
+	}},
+}
+
+// builtinSequenceRule возвращает встроенное правило формы серий по ID
+func builtinSequenceRule(id string) (*SequenceRule, bool) {
+	for i := range BuiltinSequenceRules {
+		if BuiltinSequenceRules[i].Name == id {
+			rule := BuiltinSequenceRules[i]
+			return &rule, true
+		}
+	}
+	return nil, false
+}
+
+func (r *SequenceRule) ID() string       { return r.Name }
+func (r *SequenceRule) Severity() string { return r.Level }
+
+// Window возвращает количество последних событий, которое читают условия правила
+func (r *SequenceRule) Window() int {
+	window := 0
+	for _, condition := range r.Conditions {
+		if n := condition.window(); n > window {
+			window = n
+		}
+	}
+	return window
+}
+
+// Description описывает правило по условиям
+func (r *SequenceRule) Description(cfg Config) string {
+	if r.Text != "" {
+		return r.Text
+	}
+	parts := make([]string, len(r.Conditions))
+	for i, condition := range r.Conditions {
+		events := strings.Join(strings.Split(condition.Events, ""), "/")
+		switch condition.Kind {
+		case SequenceRun:
+			parts[i] = fmt.Sprintf("%d+ событий %s подряд", condition.Length, events)
+		case SequenceAlternate:
+			parts[i] = fmt.Sprintf("%d+ событий чередуются %s", condition.Length, events)
+		case SequenceCount:
+			parts[i] = fmt.Sprintf("%d+ из последних %d событий - %s", condition.Length, condition.Window, events)
+		case SequenceStreak:
+			parts[i] = fmt.Sprintf("%s >= %d", condition.Counter, condition.Length)
+		}
+	}
+	return strings.Join(parts, " или ")
+}
+
+// Match проверяет условия правила
+func (r *SequenceRule) Match(ctx PatternContext) bool {
+	for _, condition := range r.Conditions {
+		if condition.match(ctx) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate проверяет правило из конфигурации
+func (r *SequenceRule) Validate() error {
+	if r.Name == "" || strings.Contains(r.Name, "_") {
+		return fmt.Errorf("pattern rule %q: id must be non-empty and must not contain '_'", r.Name)
+	}
+	if _, ok := severityRank[r.Level]; !ok {
+		return fmt.Errorf("pattern rule %s: unknown severity %q (expected %s, %s or %s)", r.Name, r.Level, SeverityLow, SeverityMedium, SeverityHigh)
+	}
+	if len(r.Conditions) == 0 {
+		return fmt.Errorf("pattern rule %s: no conditions", r.Name)
+	}
+	for _, condition := range r.Conditions {
+		if err := condition.validate(); err != nil {
+			return fmt.Errorf("pattern rule %s: %v", r.Name, err)
+		}
+	}
+	return nil
+}
+
+func (c SequenceCondition) validate() error {
+	if c.Length < 1 {
+		return fmt.Errorf("%s: length must be at least 1, got %d", c.Kind, c.Length)
+	}
+	if c.Kind == SequenceStreak {
+		if _, ok := streakCounter(TrainerRecord{}, c.Counter); !ok {
+			return fmt.Errorf("streak: unknown counter %q (expected uf, ux or ul)", c.Counter)
+		}
+		return nil
+	}
+
+	if c.Events == "" || strings.Trim(c.Events, "FXL") != "" {
+		return fmt.Errorf("%s: events must be a combination of F, X and L, got %q", c.Kind, c.Events)
+	}
+	switch c.Kind {
+	case SequenceRun:
+	case SequenceAlternate:
+		if len(c.Events) < 2 {
+			return fmt.Errorf("alternate: events must contain at least two outcomes, got %q", c.Events)
+		}
+	case SequenceCount:
+		if c.Window < c.Length {
+			return fmt.Errorf("count: window must be at least length %d, got %d", c.Length, c.Window)
+		}
+	default:
+		return fmt.Errorf("unknown condition kind %q (expected %s, %s, %s or %s)",
+			c.Kind, SequenceRun, SequenceAlternate, SequenceCount, SequenceStreak)
+	}
+	return nil
+}
+
+// window возвращает количество последних событий, которое читает условие
+func (c SequenceCondition) window() int {
+	switch c.Kind {
+	case SequenceRun, SequenceAlternate:
+		return c.Length
+	case SequenceCount:
+		return c.Window
+	}
+	return 0
+}
+
+func (c SequenceCondition) match(ctx PatternContext) bool {
+	if c.Kind == SequenceStreak {
+		value, _ := streakCounter(ctx.Record, c.Counter)
+		return value >= float64(c.Length)
+	}
+
+	n := c.window()
+	if n == 0 || len(ctx.RecentEvents) < n {
+		return false
+	}
+	last := ctx.RecentEvents[len(ctx.RecentEvents)-n:]
+
+	switch c.Kind {
+	case SequenceRun:
+		for _, event := range last {
+			if !strings.Contains(c.Events, event) {
+				return false
+			}
+		}
+		return true
+	case SequenceAlternate:
+		for i, event := range last {
+			if !strings.Contains(c.Events, event) || (i > 0 && event == last[i-1]) {
+				return false
+			}
+		}
+		return true
+	case SequenceCount:
+		count := 0
+		for _, event := range last {
+			if strings.Contains(c.Events, event) {
+				count++
+			}
+		}
+		return count >= c.Length
+	}
+	return false
+}
+
+// streakCounter возвращает счетчик серии записи по имени
+func streakCounter(record TrainerRecord, name string) (float64, bool) {
+	switch name {
+	case "uf":
+		return record.UF, true
+	case "ux":
+		return record.UX, true
+	case "ul":
+		return record.UL, true
+	}
+	return 0, false
+}
@@ -0,0 +1,151 @@
+package trainer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/holygun/go-trainer/common"
+)
+
+// Session состояние живой игры: последняя рассчитанная запись, стратегия
+// с конфигурацией и ставка, рекомендованная на еще не сыгранное событие
+type Session struct {
+	Strategy     string         `json:"strategy"`
+	Hockey       bool           `json:"hockey"`
+	Config       Config         `json:"config"`
+	Last         TrainerRecord  `json:"last"`
+	RecentEvents []string       `json:"recent_events"`
+	Pending      *PendingAdvice `json:"pending,omitempty"`
+	Bus          *EventBus      `json:"-"` // Получатели событий расчета (не сохраняется)
+}
+
+// PendingAdvice рекомендованные ставки на событие, результат которого еще неизвестен
+type PendingAdvice struct {
+	Odds     Odds        `json:"odds"`
+	Decision BetDecision `json:"decision"`
+}
+
+// NewSession создает сессию, прогоняя стратегию по истории событий.
+// История обрабатывается так же, как в GenerateRecordsFromEvents, и
+// останавливается на первом несыгранном событии (N).
+func NewSession(strategy Strategy, flags Flags, events []common.Event) *Session {
+	played := []common.Event{}
+	eventStrings := []string{}
+	for _, event := range events {
+		if event.Result == "N" {
+			break
+		}
+		played = append(played, event)
+		eventStrings = append(eventStrings, event.Result)
+	}
+
+	session := &Session{
+		Strategy:     strategy.Name(),
+		Hockey:       flags.Hockey,
+		Config:       flags.EffectiveConfig(),
+		Last:         TrainerRecord{Result: "N", Total: 0, Bankroll: flags.EffectiveConfig().Bankroll.Capital},
+		RecentEvents: []string{},
+		Bus:          flags.Bus,
+	}
+
+	records := GenerateRecordsFromEvents(played, flags, strategy)
+	if len(records) > 0 {
+		session.Last = records[len(records)-1]
+	}
+
+	window := NewPatternDetector(session.Config).windowSize
+	if len(eventStrings) > window {
+		eventStrings = eventStrings[len(eventStrings)-window:]
+	}
+	session.RecentEvents = append(session.RecentEvents, eventStrings...)
+
+	return session
+}
+
+// LoadSession читает сессию из JSON файла
+func LoadSession(filename string) (*Session, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	session := Session{Config: DefaultConfig()}
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("invalid session file %s: %v", filename, err)
+	}
+	if _, err := GetStrategy(session.Strategy); err != nil {
+		return nil, err
+	}
+	if err := session.Config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config in session %s: %v", filename, err)
+	}
+
+	return &session, nil
+}
+
+// Save сохраняет сессию в JSON файл
+func (s *Session) Save(filename string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
+
+// flags возвращает флаги, с которыми сессия вызывает стратегию
+func (s *Session) flags() Flags {
+	return Flags{
+		Strategy: s.Strategy,
+		Hockey:   s.Hockey,
+		Config:   &s.Config,
+		Bus:      s.Bus,
+	}
+}
+
+// Advise рассчитывает ставки на следующее событие с заданными коэффициентами
+// и запоминает их до получения результата
+func (s *Session) Advise(odds Odds) (BetDecision, error) {
+	strategy, err := GetStrategy(s.Strategy)
+	if err != nil {
+		return BetDecision{}, err
+	}
+
+	decision := decideWithLimits(strategy, &s.Last, odds, s.flags())
+	s.Pending = &PendingAdvice{Odds: odds, Decision: decision}
+
+	return decision, nil
+}
+
+// Settle применяет результат к рекомендованным ставкам и продвигает сессию
+func (s *Session) Settle(result string) (TrainerRecord, error) {
+	result = strings.ToUpper(strings.TrimSpace(result))
+	if result != "F" && result != "X" && result != "L" {
+		return TrainerRecord{}, fmt.Errorf("invalid result %q: expected F, X or L", result)
+	}
+	if s.Pending == nil {
+		return TrainerRecord{}, fmt.Errorf("no pending advice: run advise with odds first")
+	}
+
+	current := TrainerRecord{
+		EventNumber: s.Last.EventNumber + 1,
+		Result:      result,
+		OddF:        s.Pending.Odds.OddF,
+		OddX:        s.Pending.Odds.OddX,
+		OddL:        s.Pending.Odds.OddL,
+	}
+	Settle(&current, &s.Last, s.Pending.Decision, s.flags())
+
+	detector := NewPatternDetector(s.Config)
+	detector.bus = s.Bus
+	detector.strategy = s.Strategy
+	detector.recentEvents = append(detector.recentEvents, s.RecentEvents...)
+	current.setPatterns(detector.AddEvent(result, current.EventNumber, current))
+
+	s.Last = current
+	s.RecentEvents = detector.recentEvents
+	s.Pending = nil
+
+	return current, nil
+}
@@ -0,0 +1,137 @@
+package trainer
+
+// Settle применяет результат события к решению стратегии и заполняет
+// ставки, убытки, итог и серии в current.
+//
+// Для исхода-победителя убыток становится равным Residual, проигравшие
+// исходы увеличивают убыток на свою ставку (за вычетом Coverage, если
+// выиграл исход Support). Событие, пропущенное паузой (decision.Skipped),
+// не меняет убытки и серии. Результат "N" означает еще не сыгранное событие,
+// его расчет задает decision.Pending.
+func Settle(current, previous *TrainerRecord, decision BetDecision, flags Flags) {
+	losses := decision.Losses
+	streaks := PerOutcome{F: previous.UF, X: previous.UX, L: previous.UL}
+	total := decision.Total
+	bankroll := previous.Bankroll
+
+	// Сработавший лимит с остановкой: ставки не сделаны, событие не рассчитывается
+	stopped := decision.Limit == OnCapStop || decision.Limit == LimitRuin
+
+	switch {
+	case stopped:
+	case decision.Skipped && current.Result != "N":
+		// Событие пропущено паузой: убытки, серии и итог не меняются
+	case current.Result == "F" || current.Result == "X" || current.Result == "L":
+		for _, outcome := range Outcomes {
+			if outcome == current.Result {
+				streaks.Set(outcome, 0)
+				losses.Set(outcome, decision.Residual.Get(outcome))
+				continue
+			}
+			streaks.Set(outcome, streaks.Get(outcome)+1)
+			carry := decision.Bets.Get(outcome)
+			if decision.Support != "" && current.Result == decision.Support {
+				carry -= decision.Coverage.Get(outcome)
+			}
+			losses.Set(outcome, losses.Get(outcome)+carry)
+		}
+		total += decision.Profit
+		if flags.EffectiveConfig().Bankroll.Capital > 0 {
+			odds := Odds{OddF: current.OddF, OddX: current.OddX, OddL: current.OddL}
+			bankroll = settleBankroll(bankroll, decision.Bets, odds, current.Result)
+		}
+	case current.Result == "N":
+		switch {
+		case decision.Pending == PendingCarry:
+			total += decision.Profit
+		case flags.Real:
+			streaks = PerOutcome{F: -1, X: -1, L: -1}
+			losses = PerOutcome{F: -1, X: -1, L: -1}
+		case !flags.Testing:
+			flags.Bus.Debugf("Event %d: result N outside real games, losses and streaks kept", current.EventNumber)
+		}
+	}
+
+	flags.Bus.Debugf("Event %d: total FINAL %.0f", current.EventNumber, total)
+	flags.Bus.Debugf("Event %d: END GAME: lossF: %.0f, lossX: %.0f, lossL: %.0f",
+		current.EventNumber, losses.F, losses.X, losses.L)
+
+	current.BetF = decision.Bets.F
+	current.BetX = decision.Bets.X
+	current.BetL = decision.Bets.L
+	current.LossF = losses.F
+	current.LossX = losses.X
+	current.LossL = losses.L
+	current.Total = total
+	current.UF = streaks.F
+	current.UX = streaks.X
+	current.UL = streaks.L
+	current.Bankroll = bankroll
+	current.Limit = decision.Limit
+	current.Reaction = decision.Reaction
+	current.Paused = decision.Paused
+	current.Trace = decision.Trace
+
+	if !stopped && current.Result != "N" {
+		flags.Bus.Emit(Settled{
+			Strategy:    decision.Strategy,
+			EventNumber: current.EventNumber,
+			Result:      current.Result,
+			Bets:        decision.Bets,
+			Losses:      losses,
+			Streaks:     streaks,
+			Total:       total,
+		})
+	}
+}
+
+// applyStrategy принимает решение стратегии по коэффициентам current,
+// приводит его к лимитам ставок и рассчитывает событие
+func applyStrategy(strategy Strategy, current, previous *TrainerRecord, flags Flags) BetDecision {
+	odds := Odds{OddF: current.OddF, OddX: current.OddX, OddL: current.OddL}
+	decision := decideWithLimits(strategy, previous, odds, flags)
+	Settle(current, previous, decision, flags)
+	return decision
+}
+
+// decideWithLimits принимает решение стратегии, приводит его к лимитам ставок
+// и сообщает подписчикам flags.Bus о списаниях, лимитах и ставках
+func decideWithLimits(strategy Strategy, previous *TrainerRecord, odds Odds, flags Flags) BetDecision {
+	eventNumber := previous.EventNumber + 1
+	decision := decide(strategy, previous, odds, flags)
+
+	// Итог решения уже учитывает списания реакции на паттерн
+	if written := previous.Total - decision.Total; written > 0 {
+		reason := decision.Reaction
+		if reason == "" {
+			reason = decision.Strategy
+		}
+		flags.Bus.Emit(LossWrittenOff{Strategy: decision.Strategy, EventNumber: eventNumber, Amount: written, Reason: reason})
+	}
+
+	limited := applyLimits(decision, odds, flags.EffectiveConfig(), previous.Bankroll)
+	if limited.Limit != "" {
+		flags.Bus.Emit(CapHit{Strategy: decision.Strategy, EventNumber: eventNumber, Limit: limited.Limit,
+			Planned: decision.Bets, Bets: limited.Bets})
+	}
+	if written := decision.Total - limited.Total; written > 0 {
+		flags.Bus.Emit(LossWrittenOff{Strategy: decision.Strategy, EventNumber: eventNumber, Amount: written, Reason: limited.Limit})
+	}
+
+	flags.Bus.Emit(BetDecided{
+		Strategy:    limited.Strategy,
+		EventNumber: eventNumber,
+		Odds:        PerOutcome{F: odds.OddF, X: odds.OddX, L: odds.OddL},
+		Bets:        limited.Bets,
+		Losses:      limited.Losses,
+		Total:       limited.Total,
+		Reaction:    limited.Reaction,
+		Limit:       limited.Limit,
+	})
+	return limited
+}
+
+// Stopped сообщает, что на событии сработал лимит, останавливающий расчет
+func (r TrainerRecord) Stopped() bool {
+	return r.Limit == OnCapStop || r.Limit == LimitRuin
+}
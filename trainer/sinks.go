@@ -0,0 +1,101 @@
+package trainer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+)
+
+// ConsoleSink печатает описание события строкой, как раньше печатал тренажер
+type ConsoleSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewConsoleSink создает консольный получатель, пишущий в w
+func NewConsoleSink(w io.Writer) *ConsoleSink {
+	return &ConsoleSink{w: w}
+}
+
+func (s *ConsoleSink) Handle(event TrainerEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintln(s.w, event.Message())
+}
+
+// JSONSink пишет события JSON-строками: {"level":...,"kind":...,<поля события>}
+type JSONSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONSink создает получатель JSON-строк, пишущий в w
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{w: w}
+}
+
+func (s *JSONSink) Handle(event TrainerEvent) {
+	fields := eventFields(event)
+	fields["level"] = event.Level().String()
+	fields["kind"] = event.Kind()
+
+	line, err := json.Marshal(fields)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w.Write(append(line, '\n'))
+}
+
+// SlogSink передает события в log/slog: сообщение - описание события,
+// атрибуты - тип и поля события
+type SlogSink struct {
+	logger *slog.Logger
+}
+
+// NewSlogSink создает получатель, пишущий в logger
+func NewSlogSink(logger *slog.Logger) *SlogSink {
+	return &SlogSink{logger: logger}
+}
+
+func (s *SlogSink) Handle(event TrainerEvent) {
+	fields := eventFields(event)
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrs := []slog.Attr{slog.String("kind", event.Kind())}
+	for _, key := range keys {
+		attrs = append(attrs, slog.Any(key, fields[key]))
+	}
+	s.logger.LogAttrs(context.Background(), SlogLevel(event.Level()), event.Message(), attrs...)
+}
+
+// eventFields возвращает поля события по его JSON-тегам
+func eventFields(event TrainerEvent) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if data, err := json.Marshal(event); err == nil {
+		json.Unmarshal(data, &fields)
+	}
+	return fields
+}
+
+// SlogLevel переводит уровень тренажера в уровень slog
+func SlogLevel(level Level) slog.Level {
+	switch level {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	}
+	return slog.LevelError
+}
@@ -2,16 +2,103 @@ package trainer
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
-// Strategy интерфейс для различных стратегий ставок
+// Strategy интерфейс для различных стратегий ставок.
+// Стратегия только принимает решение о ставках (Decide) по предыдущему
+// состоянию и коэффициентам; применение результата выполняет Settle.
 type Strategy interface {
 	Name() string
 	Description() string
-	Calculate(current, previous *TrainerRecord, flags Flags)
+	Decide(previous *TrainerRecord, odds Odds, flags Flags) BetDecision
 }
 
+// Odds коэффициенты на исходы одного события
+type Odds struct {
+	OddF float64
+	OddX float64
+	OddL float64
+}
+
+// Get возвращает коэффициент исхода F, X или L
+func (o Odds) Get(outcome string) float64 {
+	switch outcome {
+	case "F":
+		return o.OddF
+	case "X":
+		return o.OddX
+	case "L":
+		return o.OddL
+	}
+	return 0
+}
+
+// PerOutcome значения по исходам F, X и L
+type PerOutcome struct {
+	F float64 `json:"F" yaml:"F"`
+	X float64 `json:"X" yaml:"X"`
+	L float64 `json:"L" yaml:"L"`
+}
+
+// Get возвращает значение для исхода F, X или L
+func (p PerOutcome) Get(outcome string) float64 {
+	switch outcome {
+	case "F":
+		return p.F
+	case "X":
+		return p.X
+	case "L":
+		return p.L
+	}
+	return 0
+}
+
+// Set устанавливает значение для исхода F, X или L
+func (p *PerOutcome) Set(outcome string, value float64) {
+	switch outcome {
+	case "F":
+		p.F = value
+	case "X":
+		p.X = value
+	case "L":
+		p.L = value
+	}
+}
+
+// Sum возвращает сумму значений по всем исходам
+func (p PerOutcome) Sum() float64 {
+	return p.F + p.X + p.L
+}
+
+// Outcomes исходы события в порядке F, X, L
+var Outcomes = []string{"F", "X", "L"}
+
+// BetDecision решение стратегии о ставках на одно событие
+type BetDecision struct {
+	Strategy string     // Имя стратегии, принявшей решение
+	Bets     PerOutcome // Ставки по исходам
+	Losses   PerOutcome // Убытки, под которые рассчитаны ставки
+	Residual PerOutcome // Остаток убытка исхода после его выигрыша (обычно 0)
+	Coverage PerOutcome // Часть ставки исхода, покрываемая выигрышем Support
+	Support  string     // Исход, выигрыш которого покрывает Coverage ("" - нет)
+	Total    float64    // Итог до расчета события (после списаний)
+	Profit   float64    // Плановая прибыль при рассчитанном событии
+	Limit    string     // Сработавший лимит ставок ("" - не сработал)
+	Reaction string     // Примененная реакция на паттерн ("" - нет)
+	Paused   int        // Сколько событий еще пропустить после текущего
+	Skipped  bool       // Событие пропущено паузой: ставок нет, убытки и серии не меняются
+	Trace    Trace      // Промежуточные значения, из которых получены ставки
+	Pending  string     // Расчет несыгранного события: PendingMark или PendingCarry
+}
+
+// Расчет несыгранного события (результат "N")
+const (
+	PendingMark  = ""      // С flags.Real убытки и серии -1, иначе не меняются; итог не меняется
+	PendingCarry = "carry" // Убытки решения и серии сохраняются, итог растет на Profit
+)
+
 // Регистр доступных стратегий
 var strategies = map[string]Strategy{}
 
@@ -20,20 +107,42 @@ func RegisterStrategy(strategy Strategy) {
 	strategies[strategy.Name()] = strategy
 }
 
+// VersionedStrategy стратегия с версией расчета. Версию нужно менять при
+// изменении расчета ставок, чтобы trainer real пересчитал .actual файлы
+// (tests/realgame_test.go сверяет версию с хешем эталонного расчета).
+type VersionedStrategy interface {
+	Strategy
+	Version() string
+}
+
+// StrategyVersion возвращает версию стратегии ("0" - версия не объявлена)
+func StrategyVersion(strategy Strategy) string {
+	if vs, ok := strategy.(VersionedStrategy); ok {
+		return vs.Version()
+	}
+	return "0"
+}
+
 // GetStrategy возвращает стратегию по имени
 func GetStrategy(name string) (Strategy, error) {
 	strategy, exists := strategies[name]
 	if !exists {
-		availableStrategies := make([]string, 0, len(strategies))
-		for k := range strategies {
-			availableStrategies = append(availableStrategies, k)
-		}
 		return nil, fmt.Errorf("стратегия '%s' не найдена. Доступные стратегии: %s",
-			name, strings.Join(availableStrategies, ", "))
+			name, strings.Join(StrategyNames(), ", "))
 	}
 	return strategy, nil
 }
 
+// StrategyNames возвращает отсортированные имена зарегистрированных стратегий
+func StrategyNames() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func init() {
 	RegisterStrategy(&XLDropStrategy{})
 	RegisterStrategy(&XLWithSupportStrategy{})
@@ -0,0 +1,687 @@
+package trainer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Режимы перебора параметров
+const (
+	SweepGrid   = "grid"   // Все комбинации значений
+	SweepRandom = "random" // Случайные комбинации
+	SweepCoord  = "coord"  // Покоординатный спуск
+)
+
+// Параметры конфигурации, которые можно перебирать наравне с параметрами стратегии
+const (
+	SweepDefaultBet = "default_bet"
+	SweepRoundUp    = "round_up"
+)
+
+// SweepMetrics показатели набора параметров по всем последовательностям
+type SweepMetrics struct {
+	Total     float64 // Средний итоговый результат
+	MinTotal  float64 // Худший итоговый результат
+	Equity    float64 // Средний итоговый капитал с учетом неотыгранных убытков
+	PeakStake float64 // Максимальная сумма ставок на событие
+	PeakBet   float64 // Максимальная одиночная ставка
+	PeakLoss  float64 // Максимальный неотыгранный убыток по исходу
+	Drawdown  float64 // Максимальная просадка капитала
+	Ruined    float64 // Доля последовательностей, остановленных банком или лимитом
+}
+
+// SweepMetricNames имена показателей для целевой функции и ограничений
+var SweepMetricNames = []string{"total", "min_total", "equity", "peak_stake", "peak_bet", "peak_loss", "drawdown", "ruined"}
+
+// Get возвращает показатель по имени
+func (m SweepMetrics) Get(name string) (float64, bool) {
+	switch name {
+	case "total":
+		return m.Total, true
+	case "min_total":
+		return m.MinTotal, true
+	case "equity":
+		return m.Equity, true
+	case "peak_stake":
+		return m.PeakStake, true
+	case "peak_bet":
+		return m.PeakBet, true
+	case "peak_loss":
+		return m.PeakLoss, true
+	case "drawdown":
+		return m.Drawdown, true
+	case "ruined":
+		return m.Ruined, true
+	}
+	return 0, false
+}
+
+// SweepDimension перебираемый параметр и его значения
+type SweepDimension struct {
+	Name   string
+	Values []float64
+}
+
+// ParseSweepDimension парсит диапазон вида "name=min:max:step" или
+// список значений "name=v1,v2,v3"
+func ParseSweepDimension(s string) (SweepDimension, error) {
+	name, spec, ok := strings.Cut(s, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || spec == "" {
+		return SweepDimension{}, fmt.Errorf("invalid range %q: expected name=min:max:step or name=v1,v2", s)
+	}
+
+	dim := SweepDimension{Name: name}
+	if parts := strings.Split(spec, ":"); len(parts) == 3 {
+		var bounds [3]float64
+		for i, part := range parts {
+			value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return SweepDimension{}, fmt.Errorf("invalid range %q: %v", s, err)
+			}
+			bounds[i] = value
+		}
+		min, max, step := bounds[0], bounds[1], bounds[2]
+		if step <= 0 || max < min {
+			return SweepDimension{}, fmt.Errorf("invalid range %q: expected min <= max and step > 0", s)
+		}
+		for i := 0; ; i++ {
+			// Считаем от min, чтобы не накапливать ошибку округления шага
+			value := math.Round((min+float64(i)*step)*1e9) / 1e9
+			if value > max+1e-9 {
+				break
+			}
+			dim.Values = append(dim.Values, value)
+		}
+		return dim, nil
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return SweepDimension{}, fmt.Errorf("invalid range %q: %v", s, err)
+		}
+		dim.Values = append(dim.Values, value)
+	}
+	return dim, nil
+}
+
+// SweepConstraint ограничение на показатель, например "total>=1000000"
+type SweepConstraint struct {
+	Metric string
+	Op     string // ">=" или "<="
+	Value  float64
+}
+
+// ParseSweepConstraint парсит ограничение вида "metric>=value" или "metric<=value"
+func ParseSweepConstraint(s string) (SweepConstraint, error) {
+	for _, op := range []string{">=", "<="} {
+		metric, rawValue, ok := strings.Cut(s, op)
+		if !ok {
+			continue
+		}
+		metric = strings.TrimSpace(metric)
+		if _, known := (SweepMetrics{}).Get(metric); !known {
+			return SweepConstraint{}, fmt.Errorf("invalid constraint %q: unknown metric %q (known: %s)", s, metric, strings.Join(SweepMetricNames, ", "))
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(rawValue), 64)
+		if err != nil {
+			return SweepConstraint{}, fmt.Errorf("invalid constraint %q: %v", s, err)
+		}
+		return SweepConstraint{Metric: metric, Op: op, Value: value}, nil
+	}
+	return SweepConstraint{}, fmt.Errorf("invalid constraint %q: expected metric>=value or metric<=value", s)
+}
+
+// Holds проверяет ограничение
+func (c SweepConstraint) Holds(m SweepMetrics) bool {
+	value, _ := m.Get(c.Metric)
+	if c.Op == ">=" {
+		return value >= c.Value
+	}
+	return value <= c.Value
+}
+
+func (c SweepConstraint) String() string {
+	return c.Metric + c.Op + strconv.FormatFloat(c.Value, 'f', -1, 64)
+}
+
+// SweepObjective целевая функция перебора: показатель, направление и ограничения
+type SweepObjective struct {
+	Metric      string
+	Maximize    bool
+	Constraints []SweepConstraint
+}
+
+// ParseSweepObjective парсит целевую функцию вида "min:peak_stake" или "max:total"
+func ParseSweepObjective(s string) (SweepObjective, error) {
+	direction, metric, ok := strings.Cut(s, ":")
+	if !ok || (direction != "min" && direction != "max") {
+		return SweepObjective{}, fmt.Errorf("invalid objective %q: expected min:metric or max:metric", s)
+	}
+	if _, known := (SweepMetrics{}).Get(metric); !known {
+		return SweepObjective{}, fmt.Errorf("invalid objective %q: unknown metric %q (known: %s)", s, metric, strings.Join(SweepMetricNames, ", "))
+	}
+	return SweepObjective{Metric: metric, Maximize: direction == "max"}, nil
+}
+
+func (o SweepObjective) String() string {
+	direction := "min"
+	if o.Maximize {
+		direction = "max"
+	}
+	parts := []string{direction + ":" + o.Metric}
+	for _, c := range o.Constraints {
+		parts = append(parts, c.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// better сообщает, что кандидат a лучше b: без ошибки лучше ошибки,
+// выполненные ограничения лучше невыполненных, затем по показателю.
+// При равных показателях лучше кандидат с большим итоговым капиталом.
+func (o SweepObjective) better(a, b SweepCandidate) bool {
+	if (a.Err == "") != (b.Err == "") {
+		return a.Err == ""
+	}
+	if a.Feasible != b.Feasible {
+		return a.Feasible
+	}
+	va, _ := a.Metrics.Get(o.Metric)
+	vb, _ := b.Metrics.Get(o.Metric)
+	if va == vb {
+		return a.Metrics.Equity > b.Metrics.Equity
+	}
+	if o.Maximize {
+		return va > vb
+	}
+	return va < vb
+}
+
+// SweepSequence последовательность событий с коэффициентами для перебора
+type SweepSequence struct {
+	Name   string
+	Hockey bool
+	Events []string // От старых к новым
+	Odds   []Odds
+}
+
+// RandomSweepSequences генерирует n случайных последовательностей как в
+// RunMonteCarlo: последовательность i использует seed+i
+func RandomSweepSequences(flags Flags, n, events int, seed int64) []SweepSequence {
+	sequences := make([]SweepSequence, n)
+	for i := range sequences {
+		sequenceSeed := seed + int64(i)
+		eventsFromOldest, odds := randomSequence(flags, events, sequenceSeed)
+		sequences[i] = SweepSequence{
+			Name:   "seed-" + strconv.FormatInt(sequenceSeed, 10),
+			Hockey: flags.Hockey,
+			Events: eventsFromOldest,
+			Odds:   odds,
+		}
+	}
+	return sequences
+}
+
+// InputSweepSequences читает последовательности из .input файлов каталога
+// (например, real-games). События после первого несыгранного (N) не берутся,
+// вид спорта определяется как в RecomputeRealGame: по тегам имени файла и .meta.
+func InputSweepSequences(dir string) ([]SweepSequence, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.input"))
+	if err != nil {
+		return nil, err
+	}
+
+	var sequences []SweepSequence
+	for _, file := range files {
+		events, err := ReadInputFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		strategyName, tags := ParseRealGameName(file)
+		strategyName, _, _ = strings.Cut(strategyName, "_")
+		meta, err := LoadRealGameMeta(file)
+		if err != nil {
+			return nil, err
+		}
+		settings, err := meta.Resolve(strategyName, HasTag(append(tags, meta.Tags...), TagHockey), DefaultConfig())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(file), ".input")
+		sequence := SweepSequence{Name: name, Hockey: settings.Hockey}
+		for _, event := range events {
+			if event.Result == "N" {
+				break
+			}
+			sequence.Events = append(sequence.Events, event.Result)
+			sequence.Odds = append(sequence.Odds, Odds{OddF: event.OddF, OddX: event.OddX, OddL: event.OddL})
+		}
+		if len(sequence.Events) > 0 {
+			sequences = append(sequences, sequence)
+		}
+	}
+	return sequences, nil
+}
+
+// CSVSweepSequences читает последовательности из CSV файлов каталога
+// (например, results): берутся результаты и коэффициенты событий
+func CSVSweepSequences(dir string) ([]SweepSequence, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.csv"))
+	if err != nil {
+		return nil, err
+	}
+
+	var sequences []SweepSequence
+	for _, file := range files {
+		records, err := ReadCSV(file)
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(records, func(i, j int) bool {
+			return records[i].EventNumber < records[j].EventNumber
+		})
+		sequence := SweepSequence{Name: strings.TrimSuffix(filepath.Base(file), ".csv")}
+		for _, record := range records {
+			if record.Result == "N" {
+				break
+			}
+			sequence.Events = append(sequence.Events, record.Result)
+			sequence.Odds = append(sequence.Odds, Odds{OddF: record.OddF, OddX: record.OddX, OddL: record.OddL})
+		}
+		if len(sequence.Events) > 0 {
+			sequences = append(sequences, sequence)
+		}
+	}
+	return sequences, nil
+}
+
+// SweepOptions параметры перебора
+type SweepOptions struct {
+	Dimensions []SweepDimension
+	Sequences  []SweepSequence
+	Objective  SweepObjective
+	Mode       string // SweepGrid, SweepRandom или SweepCoord
+	Samples    int    // Количество случайных комбинаций для SweepRandom
+	Rounds     int    // Максимум проходов покоординатного спуска
+	Workers    int    // Количество параллельных обработчиков (0 - по числу CPU)
+	Seed       int64  // Seed выбора случайных комбинаций
+}
+
+// SweepCandidate набор значений параметров и его показатели
+type SweepCandidate struct {
+	Values   []float64 // Значения в порядке SweepOptions.Dimensions
+	Metrics  SweepMetrics
+	Feasible bool   // Выполнены все ограничения
+	Err      string // Недопустимая комбинация параметров
+}
+
+// SweepReport результат перебора, кандидаты отсортированы от лучшего к худшему
+type SweepReport struct {
+	Strategy   string
+	Options    SweepOptions
+	Candidates []SweepCandidate
+}
+
+// sweeper перебирает комбинации и запоминает уже посчитанные
+type sweeper struct {
+	strategy Strategy
+	flags    Flags
+	opts     SweepOptions
+	done     map[string]SweepCandidate
+	order    []string
+}
+
+// RunSweep оценивает комбинации параметров стратегии на последовательностях
+// opts.Sequences и ранжирует их по opts.Objective
+func RunSweep(strategy Strategy, flags Flags, opts SweepOptions) (SweepReport, error) {
+	if len(opts.Dimensions) == 0 {
+		return SweepReport{}, fmt.Errorf("no parameter ranges to sweep")
+	}
+	if len(opts.Sequences) == 0 {
+		return SweepReport{}, fmt.Errorf("no sequences to evaluate")
+	}
+
+	schema := map[string]bool{SweepDefaultBet: true, SweepRoundUp: true}
+	for _, param := range StrategyParams(strategy) {
+		schema[param.Name] = true
+	}
+	for _, dim := range opts.Dimensions {
+		if !schema[dim.Name] {
+			return SweepReport{}, fmt.Errorf("strategy %s has no parameter %q", strategy.Name(), dim.Name)
+		}
+		if len(dim.Values) == 0 {
+			return SweepReport{}, fmt.Errorf("parameter %s: empty range", dim.Name)
+		}
+	}
+
+	flags.Bus = nil
+	s := &sweeper{strategy: strategy, flags: flags, opts: opts, done: map[string]SweepCandidate{}}
+
+	switch opts.Mode {
+	case SweepGrid, "":
+		s.evaluate(s.grid())
+	case SweepRandom:
+		s.evaluate(s.random())
+	case SweepCoord:
+		s.coordinateDescent()
+	default:
+		return SweepReport{}, fmt.Errorf("unknown sweep mode %q (known: %s, %s, %s)", opts.Mode, SweepGrid, SweepRandom, SweepCoord)
+	}
+
+	candidates := make([]SweepCandidate, len(s.order))
+	for i, key := range s.order {
+		candidates[i] = s.done[key]
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return opts.Objective.better(candidates[i], candidates[j])
+	})
+
+	return SweepReport{Strategy: strategy.Name(), Options: opts, Candidates: candidates}, nil
+}
+
+// grid возвращает все комбинации значений
+func (s *sweeper) grid() [][]float64 {
+	combos := [][]float64{{}}
+	for _, dim := range s.opts.Dimensions {
+		next := make([][]float64, 0, len(combos)*len(dim.Values))
+		for _, combo := range combos {
+			for _, value := range dim.Values {
+				values := append(append([]float64{}, combo...), value)
+				next = append(next, values)
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// random возвращает opts.Samples случайных комбинаций значений
+func (s *sweeper) random() [][]float64 {
+	rng := rand.New(rand.NewSource(s.opts.Seed))
+	combos := make([][]float64, s.opts.Samples)
+	for i := range combos {
+		combos[i] = make([]float64, len(s.opts.Dimensions))
+		for j, dim := range s.opts.Dimensions {
+			combos[i][j] = dim.Values[rng.Intn(len(dim.Values))]
+		}
+	}
+	return combos
+}
+
+// coordinateDescent по очереди улучшает каждый параметр при фиксированных
+// остальных, пока проход не перестанет давать улучшение. Начинает со значений,
+// ближайших к текущей конфигурации.
+func (s *sweeper) coordinateDescent() {
+	cfg := s.flags.EffectiveConfig()
+	current := make([]float64, len(s.opts.Dimensions))
+	for i, dim := range s.opts.Dimensions {
+		start := s.configValue(cfg, dim.Name)
+		current[i] = dim.Values[0]
+		for _, value := range dim.Values {
+			if math.Abs(value-start) < math.Abs(current[i]-start) {
+				current[i] = value
+			}
+		}
+	}
+	s.evaluate([][]float64{current})
+	best := s.done[sweepKey(current)]
+
+	rounds := s.opts.Rounds
+	if rounds <= 0 {
+		rounds = 5
+	}
+	for round := 0; round < rounds; round++ {
+		improved := false
+		for i, dim := range s.opts.Dimensions {
+			combos := make([][]float64, len(dim.Values))
+			for j, value := range dim.Values {
+				combos[j] = append([]float64{}, current...)
+				combos[j][i] = value
+			}
+			s.evaluate(combos)
+			for _, combo := range combos {
+				if candidate := s.done[sweepKey(combo)]; s.opts.Objective.better(candidate, best) {
+					best = candidate
+					current = combo
+					improved = true
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+}
+
+// evaluate параллельно считает еще не посчитанные комбинации
+func (s *sweeper) evaluate(combos [][]float64) {
+	var pending [][]float64
+	for _, combo := range combos {
+		key := sweepKey(combo)
+		if _, ok := s.done[key]; ok {
+			continue
+		}
+		s.done[key] = SweepCandidate{}
+		s.order = append(s.order, key)
+		pending = append(pending, combo)
+	}
+
+	workers := s.opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]SweepCandidate, len(pending))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = s.candidate(pending[i])
+			}
+		}()
+	}
+	for i := range pending {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i, combo := range pending {
+		s.done[sweepKey(combo)] = results[i]
+	}
+}
+
+// candidate прогоняет стратегию с набором значений по всем последовательностям
+func (s *sweeper) candidate(values []float64) SweepCandidate {
+	candidate := SweepCandidate{Values: values}
+
+	cfg := s.flags.EffectiveConfig()
+	for i, dim := range s.opts.Dimensions {
+		switch dim.Name {
+		case SweepDefaultBet:
+			cfg.DefaultBetF = values[i]
+		case SweepRoundUp:
+			cfg.RoundUp = values[i]
+		default:
+			cfg.SetParam(s.strategy.Name(), dim.Name, values[i])
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		candidate.Err = err.Error()
+		return candidate
+	}
+
+	metrics := SweepMetrics{MinTotal: math.Inf(1)}
+	stopped := 0
+	for _, sequence := range s.opts.Sequences {
+		flags := s.flags
+		flags.Config = &cfg
+		flags.Hockey = sequence.Hockey
+		provider := NewFixedOddsProvider(sequence.Odds, NewRandomOddsProvider(flags.Seed, flags), flags)
+		records := Simulate(sequence.Events, provider, flags, s.strategy)
+		if len(records) == 0 {
+			continue
+		}
+
+		last := records[len(records)-1]
+		metrics.Total += last.Total
+		metrics.MinTotal = math.Min(metrics.MinTotal, last.Total)
+		if last.Stopped() {
+			stopped++
+		}
+		for _, record := range records {
+			metrics.PeakStake = math.Max(metrics.PeakStake, record.BetF+record.BetX+record.BetL)
+			metrics.PeakBet = math.Max(metrics.PeakBet, math.Max(record.BetF, math.Max(record.BetX, record.BetL)))
+			metrics.PeakLoss = math.Max(metrics.PeakLoss, math.Max(record.LossF, math.Max(record.LossX, record.LossL)))
+		}
+		drawdown := CalculateDrawdown(EquityCurve(records))
+		metrics.Equity += drawdown.FinalEquity
+		metrics.Drawdown = math.Max(metrics.Drawdown, drawdown.MaxDrawdown)
+	}
+
+	n := float64(len(s.opts.Sequences))
+	metrics.Total /= n
+	metrics.Equity /= n
+	metrics.Ruined = float64(stopped) / n
+	if math.IsInf(metrics.MinTotal, 1) {
+		metrics.MinTotal = 0
+	}
+
+	candidate.Metrics = metrics
+	candidate.Feasible = true
+	for _, constraint := range s.opts.Objective.Constraints {
+		if !constraint.Holds(metrics) {
+			candidate.Feasible = false
+		}
+	}
+	return candidate
+}
+
+// configValue возвращает текущее значение перебираемого параметра
+func (s *sweeper) configValue(cfg Config, name string) float64 {
+	switch name {
+	case SweepDefaultBet:
+		return cfg.DefaultBetF
+	case SweepRoundUp:
+		return cfg.RoundUp
+	}
+	return cfg.StrategyParams(s.strategy)[name]
+}
+
+func sweepKey(values []float64) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	return strings.Join(parts, ",")
+}
+
+// PrintSweepReport выводит первые top кандидатов перебора
+func PrintSweepReport(report SweepReport, top int) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Printf("          🔍 ПЕРЕБОР ПАРАМЕТРОВ: %s\n", report.Strategy)
+	fmt.Println(strings.Repeat("=", 60))
+
+	opts := report.Options
+	feasible := 0
+	for _, candidate := range report.Candidates {
+		if candidate.Feasible {
+			feasible++
+		}
+	}
+	fmt.Printf("\nРежим: %s, последовательностей: %d, комбинаций: %d, подходящих: %d\n",
+		opts.Mode, len(opts.Sequences), len(report.Candidates), feasible)
+	fmt.Printf("Цель: %s\n\n", opts.Objective)
+
+	if top <= 0 || top > len(report.Candidates) {
+		top = len(report.Candidates)
+	}
+
+	fmt.Printf("%-4s", "#")
+	for _, dim := range opts.Dimensions {
+		fmt.Printf(" %11s", dim.Name)
+	}
+	fmt.Printf(" %12s %12s %11s %11s %11s %7s\n", "total", "min_total", "peak_stake", "peak_loss", "drawdown", "ruined")
+
+	for i, candidate := range report.Candidates[:top] {
+		mark := " "
+		if !candidate.Feasible {
+			mark = "✗"
+		}
+		fmt.Printf("%-3d%s", i+1, mark)
+		for _, value := range candidate.Values {
+			fmt.Printf(" %11s", strconv.FormatFloat(value, 'g', -1, 64))
+		}
+		if candidate.Err != "" {
+			fmt.Printf(" %s\n", candidate.Err)
+			continue
+		}
+		m := candidate.Metrics
+		fmt.Printf(" %12.0f %12.0f %11.0f %11.0f %11.0f %6.1f%%\n",
+			m.Total, m.MinTotal, m.PeakStake, m.PeakLoss, m.Drawdown, m.Ruined*100)
+	}
+
+	if feasible == 0 {
+		fmt.Println("\n⚠️ Ни одна комбинация не выполняет ограничения")
+	}
+}
+
+// SaveSweepCSV сохраняет всех кандидатов перебора в порядке ранга
+func SaveSweepCSV(report SweepReport, filename string, meta map[string]string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := writeMetaLine(file, meta); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	headers := []string{"rank"}
+	for _, dim := range report.Options.Dimensions {
+		headers = append(headers, dim.Name)
+	}
+	headers = append(headers, SweepMetricNames...)
+	headers = append(headers, "feasible", "error")
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+
+	for i, candidate := range report.Candidates {
+		row := []string{strconv.Itoa(i + 1)}
+		for _, value := range candidate.Values {
+			row = append(row, strconv.FormatFloat(value, 'g', -1, 64))
+		}
+		for _, name := range SweepMetricNames {
+			value, _ := candidate.Metrics.Get(name)
+			precision := 0
+			if name == "ruined" {
+				precision = 4
+			}
+			row = append(row, strconv.FormatFloat(value, 'f', precision, 64))
+		}
+		row = append(row, strconv.FormatBool(candidate.Feasible), candidate.Err)
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return writer.Error()
+}
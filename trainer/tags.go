@@ -0,0 +1,134 @@
+package trainer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// TagHockey тег хоккейных файлов: события рассчитываются с коэффициентами хоккея
+const TagHockey = "hockey"
+
+// BuiltinTags теги real-games файлов, известные без конфигурации
+var BuiltinTags = []string{TagHockey, "TEST", "PROD", "STAGING"}
+
+// TagRegistry реестр тегов real-games файлов. Имена тегов сравниваются без
+// учета регистра, в выводе используется написание при регистрации.
+type TagRegistry struct {
+	names []string
+}
+
+// NewTagRegistry создает реестр из встроенных тегов и тегов extra
+// (например, Config.Tags)
+func NewTagRegistry(extra ...string) (*TagRegistry, error) {
+	registry := &TagRegistry{}
+	for _, name := range append(append([]string{}, BuiltinTags...), extra...) {
+		if err := registry.Register(name); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// ValidateTag проверяет имя тега: тег записывается в имени файла через дефис
+// и в списке флага -tags через запятую
+func ValidateTag(name string) error {
+	if name == "" || strings.ContainsAny(name, "-,. \t/\\") {
+		return fmt.Errorf("invalid tag %q: expected a non-empty name without '-', ',', '.', spaces or slashes", name)
+	}
+	return nil
+}
+
+// Register добавляет тег; повторная регистрация не меняет реестр
+func (r *TagRegistry) Register(name string) error {
+	if err := ValidateTag(name); err != nil {
+		return err
+	}
+	if _, ok := r.Lookup(name); !ok {
+		r.names = append(r.names, name)
+	}
+	return nil
+}
+
+// Lookup возвращает зарегистрированное написание тега
+func (r *TagRegistry) Lookup(name string) (string, bool) {
+	for _, registered := range r.names {
+		if strings.EqualFold(registered, name) {
+			return registered, true
+		}
+	}
+	return "", false
+}
+
+// Names возвращает теги в порядке регистрации
+func (r *TagRegistry) Names() []string {
+	return append([]string{}, r.names...)
+}
+
+// Resolve приводит теги к зарегистрированному написанию. Ошибка перечисляет
+// незарегистрированные теги и теги реестра.
+func (r *TagRegistry) Resolve(tags []string) ([]string, error) {
+	resolved := make([]string, 0, len(tags))
+	var unknown []string
+	for _, tag := range tags {
+		name, ok := r.Lookup(tag)
+		if !ok {
+			unknown = append(unknown, tag)
+			continue
+		}
+		resolved = append(resolved, name)
+	}
+	if len(unknown) > 0 {
+		return resolved, fmt.Errorf("unknown tag(s) %s (registered: %s)", strings.Join(unknown, ", "), strings.Join(r.names, ", "))
+	}
+	return resolved, nil
+}
+
+// ParseRealGameName разбирает имя real-games файла "<стратегия>[-тег...].input":
+// "xlDrop-hockey-TEST.input" - стратегия xlDrop с тегами hockey и TEST
+func ParseRealGameName(filename string) (strategy string, tags []string) {
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	parts := strings.Split(base, "-")
+	for _, part := range parts[1:] {
+		if part != "" {
+			tags = append(tags, part)
+		}
+	}
+	return parts[0], tags
+}
+
+// HasTag сообщает, есть ли среди тегов tag (без учета регистра)
+func HasTag(tags []string, tag string) bool {
+	for _, name := range tags {
+		if strings.EqualFold(name, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// TagFilter отбор файлов по тегам: при непустом Include файл должен иметь
+// хотя бы один из этих тегов, а файл с любым тегом из Exclude пропускается
+// (Exclude сильнее Include). Пустой фильтр пропускает все файлы.
+type TagFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// Match сообщает, проходит ли файл с тегами tags фильтр, и причину пропуска
+func (f TagFilter) Match(tags []string) (bool, string) {
+	for _, tag := range f.Exclude {
+		if HasTag(tags, tag) {
+			return false, fmt.Sprintf("тег %s исключен", tag)
+		}
+	}
+	if len(f.Include) == 0 {
+		return true, ""
+	}
+	for _, tag := range f.Include {
+		if HasTag(tags, tag) {
+			return true, ""
+		}
+	}
+	return false, fmt.Sprintf("нет ни одного из тегов %s", strings.Join(f.Include, ", "))
+}
@@ -0,0 +1,193 @@
+package trainer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TraceEntry промежуточное значение, из которого стратегия вывела ставки
+type TraceEntry struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Trace промежуточные значения расчета ставок события в порядке вычисления
+type Trace []TraceEntry
+
+// NewTrace возвращает трассировку для стратегии или nil, если запись
+// промежуточных значений не включена flags.Trace. Методы nil-трассировки
+// ничего не делают, поэтому стратегия пишет значения без проверок.
+func (f Flags) NewTrace() *Trace {
+	if !f.Trace {
+		return nil
+	}
+	return &Trace{}
+}
+
+// Add добавляет числовое значение
+func (t *Trace) Add(name string, value float64) {
+	if t == nil {
+		return
+	}
+	t.AddText(name, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// AddText добавляет текстовое значение
+func (t *Trace) AddText(name, value string) {
+	if t == nil {
+		return
+	}
+	*t = append(*t, TraceEntry{Name: name, Value: value})
+}
+
+// Entries возвращает записанные значения (nil для nil-трассировки)
+func (t *Trace) Entries() Trace {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+// Get возвращает значение по имени
+func (t Trace) Get(name string) (string, bool) {
+	for _, entry := range t {
+		if entry.Name == name {
+			return entry.Value, true
+		}
+	}
+	return "", false
+}
+
+// TraceField описание промежуточного значения стратегии
+type TraceField struct {
+	Name        string
+	Description string
+}
+
+// TracedStrategy стратегия, объявляющая промежуточные значения своего расчета
+type TracedStrategy interface {
+	Strategy
+	TraceFields() []TraceField
+}
+
+// TraceColumns возвращает имена значений трассировки записей - колонки
+// широкого CSV. Новое имя встает сразу после имени, за которым оно шло
+// в записи, поэтому колонки сохраняют порядок вычисления.
+func TraceColumns(records []TrainerRecord) []string {
+	sorted := make([]TrainerRecord, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].EventNumber < sorted[j].EventNumber
+	})
+
+	names := []string{}
+	for _, record := range sorted {
+		position := -1
+		for _, entry := range record.Trace {
+			if i := indexOf(names, entry.Name); i >= 0 {
+				position = i
+				continue
+			}
+			position++
+			names = append(names[:position], append([]string{entry.Name}, names[position:]...)...)
+		}
+	}
+	return names
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// traceColumnPrefix префикс колонок трассировки в широком CSV
+const traceColumnPrefix = "trace."
+
+// Explain объясняет словами, как получены ставки события eventNumber.
+// strategy может быть nil - тогда значения трассировки выводятся без описаний.
+func Explain(records []TrainerRecord, eventNumber int, strategy Strategy) ([]string, error) {
+	var current, previous *TrainerRecord
+	for i := range records {
+		switch records[i].EventNumber {
+		case eventNumber:
+			current = &records[i]
+		case eventNumber - 1:
+			previous = &records[i]
+		}
+	}
+	if current == nil {
+		return nil, fmt.Errorf("event %d not found", eventNumber)
+	}
+	if previous == nil {
+		previous = &TrainerRecord{Result: "N"}
+	}
+
+	lines := []string{
+		fmt.Sprintf("Событие %d: результат %s, коэффициенты F=%.2f X=%.2f L=%.2f",
+			current.EventNumber, current.Result, current.OddF, current.OddX, current.OddL),
+	}
+	if !current.Match.IsZero() {
+		lines = append(lines, fmt.Sprintf("Матч: %s", current.Match))
+	}
+	lines = append(lines,
+		fmt.Sprintf("До события: убытки F=%.0f X=%.0f L=%.0f, итог %.0f, событий без F/X/L: %.0f/%.0f/%.0f",
+			previous.LossF, previous.LossX, previous.LossL, previous.Total, previous.UF, previous.UX, previous.UL))
+	if previous.Pattern != "" {
+		lines = append(lines, fmt.Sprintf("Паттерны предыдущего события: %s", strings.ReplaceAll(previous.Pattern, "_", ", ")))
+	}
+	if current.Reaction != "" {
+		lines = append(lines, fmt.Sprintf("Реакция на паттерн: %s", current.Reaction))
+	}
+	if current.Limit != "" {
+		lines = append(lines, fmt.Sprintf("Сработал лимит ставок: %s", current.Limit))
+	}
+
+	if len(current.Trace) == 0 {
+		lines = append(lines, "Промежуточные значения не записаны: пересчитайте CSV с флагом -wide")
+	} else {
+		descriptions := map[string]string{}
+		if traced, ok := strategy.(TracedStrategy); ok {
+			for _, field := range traced.TraceFields() {
+				descriptions[field.Name] = field.Description
+			}
+		}
+		lines = append(lines, "Расчет стратегии:")
+		for _, entry := range current.Trace {
+			if description, ok := descriptions[entry.Name]; ok {
+				lines = append(lines, fmt.Sprintf("  %s (%s): %s", description, entry.Name, entry.Value))
+			} else {
+				lines = append(lines, fmt.Sprintf("  %s: %s", entry.Name, entry.Value))
+			}
+		}
+	}
+
+	odds := PerOutcome{F: current.OddF, X: current.OddX, L: current.OddL}
+	bets := PerOutcome{F: current.BetF, X: current.BetX, L: current.BetL}
+	lines = append(lines, "Ставки (ставка = убыток / (коэффициент - 1), округлено вверх):")
+	for _, outcome := range Outcomes {
+		line := fmt.Sprintf("  %s: %.0f, выигрыш при %s: %.0f", outcome, bets.Get(outcome), outcome,
+			bets.Get(outcome)*(odds.Get(outcome)-1))
+		if loss, ok := current.Trace.Get("loss" + outcome); ok {
+			line += fmt.Sprintf(", покрывает убыток %s", loss)
+		}
+		if stake, ok := current.Trace.Get("stake" + outcome); ok {
+			line += fmt.Sprintf(" (ставка рассчитана на %s)", stake)
+		}
+		if bookmaker := *current.Match.Field("bookmaker" + outcome); bookmaker != "" {
+			line += fmt.Sprintf(", букмекер %s", bookmaker)
+		}
+		lines = append(lines, line)
+	}
+
+	if current.Result == "F" || current.Result == "X" || current.Result == "L" {
+		lines = append(lines, fmt.Sprintf("После события: убытки F=%.0f X=%.0f L=%.0f, итог %.0f",
+			current.LossF, current.LossX, current.LossL, current.Total))
+	}
+	return lines, nil
+}
@@ -1,14 +1,9 @@
 package trainer
 
 import (
-	"encoding/csv"
 	"fmt"
-	"math"
-	"math/rand"
-	"os"
-	"strconv"
+	"sort"
 	"strings"
-	"time"
 
 	"github.com/holygun/go-trainer/common"
 )
@@ -25,140 +20,42 @@ type Flags struct {
 	Real     bool
 	Force    bool
 	Testing  bool
+	Seed     int64
+	Config   *Config   // Конфигурация запуска (nil - DefaultConfig)
+	Bus      *EventBus // Получатели событий расчета (nil - расчет молчит)
+	Trace    bool      // Записывать в TrainerRecord.Trace промежуточные значения стратегии
 }
 
 const DEFAULT_BET = 10000
 const PARTIAL_COVERAGE_MULT = 1
 
-// Pattern определяет структуру паттерна
-type Pattern struct {
-	ID          string
-	Description string
-}
-
-// Зарегистрированные паттерны
-var patterns = []Pattern{
-	{"RED", "three or more metrics > 20 * DEFAULT_BET"},
-	{"YELLOW", "two or more metrics > 10 * DEFAULT_BET or one metric > 20 * DEFAULT_BET"},
-	{"GREEN", "one metric > 10 * DEFAULT_BET"},
-}
-
-// PatternDetector детектор паттернов
-type PatternDetector struct {
-	recentEvents []string
-	windowSize   int
-}
-
-// NewPatternDetector создает новый детектор
-func NewPatternDetector() *PatternDetector {
-	return &PatternDetector{
-		recentEvents: make([]string, 0),
-		windowSize:   10,
-	}
-}
-
-// AddEvent добавляет событие и проверяет паттерны
-func (pd *PatternDetector) AddEvent(event string, eventNumber int, record TrainerRecord) []string {
-	pd.recentEvents = append(pd.recentEvents, event)
-	if len(pd.recentEvents) > pd.windowSize {
-		pd.recentEvents = pd.recentEvents[1:]
-	}
-
-	detectedPatterns := []string{}
-
-	// Проверяем паттерны
-	for _, pattern := range patterns {
-		if pd.checkPattern(pattern, record) {
-			detectedPatterns = append(detectedPatterns, pattern.ID)
-			fmt.Printf("⚠️ Событие номер %d: обнаружен паттерн %s - %s\n", eventNumber, pattern.ID, pattern.Description)
-			break
-		}
-	}
-
-	return detectedPatterns
-}
-
-// checkPattern проверяет конкретный паттерн
-func (pd *PatternDetector) checkPattern(pattern Pattern, record TrainerRecord) bool {
-	metrics := []float64{
-		record.BetF,
-		record.BetX,
-		record.BetL,
-		record.LossF,
-		record.LossX,
-		record.LossL,
-	}
-	switch pattern.ID {
-	case "RED":
-		threshold := 20.0 * DEFAULT_BET
-		count := 0
-		for _, value := range metrics {
-			if value > threshold {
-				count += 1
-			}
-		}
-		return count >= 3
-	case "YELLOW":
-		small_threshold, big_threshold := 10.0*DEFAULT_BET, 20.0*DEFAULT_BET
-		small_count, big_count := 0, 0
-		for _, value := range metrics {
-			if value > big_threshold {
-				big_count += 1
-			}
-			if value > small_threshold {
-				small_count += 1
-			}
-		}
-		return small_count >= 2 || big_count >= 1
-	case "GREEN":
-		threshold := 10.0 * DEFAULT_BET
-		count := 0
-		for _, value := range metrics {
-			if value > threshold {
-				count += 1
-			}
-		}
-		return count >= 1
-	}
-	return false
-}
-
 // TrainerRecord представляет одну запись в CSV
 type TrainerRecord struct {
-	EventNumber int     // Номер события
-	Result      string  // F, X или L
-	OddF        float64 // Коэффициент F
-	OddX        float64 // Коэффициент X
-	OddL        float64 // Коэффициент L
-	BetF        float64 // Ставка F
-	BetX        float64 // Ставка X
-	BetL        float64 // Ставка L
-	LossF       float64 // Убыток F
-	LossX       float64 // Убыток X
-	LossL       float64 // Убыток L
-	Total       float64 // Итого
-	UF          float64 // Серия без F
-	UX          float64 // Серия без X
-	UL          float64 // Серия без L
-	Pattern     string  // Обнаруженные паттерны
-}
-
-// Config содержит конфигурацию тренажера
-type Config struct {
-	DefaultBetF float64
-	RoundUp     float64
-	OddsRanges  struct {
-		OddF        Range
-		OddX        Range
-		OddL        Range
-		MarginRange Range
-	}
-}
-
-// Range представляет диапазон значений
-type Range struct {
-	Min float64
-	Max float64
+	EventNumber int            // Номер события
+	Result      string         // F, X или L
+	OddF        float64        // Коэффициент F
+	OddX        float64        // Коэффициент X
+	OddL        float64        // Коэффициент L
+	BetF        float64        // Ставка F
+	BetX        float64        // Ставка X
+	BetL        float64        // Ставка L
+	LossF       float64        // Убыток F
+	LossX       float64        // Убыток X
+	LossL       float64        // Убыток L
+	Total       float64        // Итого
+	UF          float64        // Серия без F
+	UX          float64        // Серия без X
+	UL          float64        // Серия без L
+	Pattern     string         // Обнаруженные паттерны через "_", от более серьезных к менее серьезным
+	Severity    string         // Наибольшая серьезность обнаруженных паттернов
+	Patterns    []PatternMatch // Обнаруженные паттерны с серьезностью
+	Bankroll    float64        // Банк после события (если задан капитал)
+	Limit       string         // Сработавший лимит ставок
+	Reaction    string         // Примененная реакция на паттерн предыдущего события
+	Paused      int            // Сколько следующих событий пропустить без ставок
+	Trace       Trace          // Промежуточные значения расчета ставок
+	Match       common.Match   // Метаданные матча из расширенного .input файла (букмекеры ставок - выбранные линии)
+	LineValue   float64        // Дополнительный выигрыш от выбора линии по сравнению с одним букмекером
 }
 
 // Статистика для отчета
@@ -169,22 +66,18 @@ type Stats struct {
 	MaxBets          map[string]float64
 	MaxLosses        map[string]float64
 	MaxStreaks       map[string]int
-}
-
-var config = Config{
-	DefaultBetF: DEFAULT_BET,
-	RoundUp:     50,
-	OddsRanges: struct {
-		OddF        Range
-		OddX        Range
-		OddL        Range
-		MarginRange Range
-	}{
-		OddF:        Range{Min: 1.8, Max: 2.1},
-		OddX:        Range{Min: 3.3, Max: 3.9},
-		OddL:        Range{Min: 4.0, Max: 5.0},
-		MarginRange: Range{Min: 1.05, Max: 1.1},
-	},
+	HasBankroll      bool               // В записях есть банк (задан начальный банк)
+	FinalBankroll    float64            // Банк после последнего события
+	MinBankroll      float64            // Минимальный банк
+	HasLimits        bool               // Сработал хотя бы один лимит ставок
+	CapHits          int                // Количество событий с урезанными ставками
+	StoppedAt        int                // Событие, на котором расчет остановлен лимитом (0 - нет)
+	RuinedAt         int                // Событие, на котором банка не хватило на ставки (0 - нет)
+	Drawdown         DrawdownStats      // Просадки капитала с учетом неотыгранных убытков
+	PatternCounts    map[string]int     // Количество событий с каждым паттерном
+	ReactionCounts   map[string]int     // Количество примененных реакций на паттерны
+	Turnover         map[string]float64 // Сумма ставок по букмекерам
+	LineValue        float64            // Выигрыш от выбора линии по сравнению с одним букмекером
 }
 
 // parseEvents парсит строку событий F/X/L
@@ -220,223 +113,81 @@ func ReverseRecords(records []TrainerRecord) []TrainerRecord {
 	return result
 }
 
-// roundUp округляет значение вверх до кратного config.RoundUp
-func roundUp(value float64) float64 {
-	return math.Ceil(value/config.RoundUp) * config.RoundUp
-}
-
-// calcBet вычисляет ставку
-func calcBet(value, odd float64) float64 {
-	return roundUp(value / (odd - 1))
-}
-
-// generateOdds генерирует коэффициенты с учетом ограничений
-func generateOdds(flags Flags) (float64, float64, float64) {
-	rand.Seed(time.Now().UnixNano())
-	maxAttempts := 1000
-
-	for i := 0; i < maxAttempts; i++ {
-		oddF := config.OddsRanges.OddF.Min + rand.Float64()*(config.OddsRanges.OddF.Max-config.OddsRanges.OddF.Min)
-		oddX := config.OddsRanges.OddX.Min + rand.Float64()*(config.OddsRanges.OddX.Max-config.OddsRanges.OddX.Min)
-		oddL := config.OddsRanges.OddL.Min + rand.Float64()*(config.OddsRanges.OddL.Max-config.OddsRanges.OddL.Min)
-
-		margin := 1/oddF + 1/oddX + 1/oddL
-
-		if margin >= config.OddsRanges.MarginRange.Min && margin <= config.OddsRanges.MarginRange.Max {
-			// Округляем до 2 знаков после запятой
-			oddF = math.Round(oddF*100) / 100
-			oddX = math.Round(oddX*100) / 100
-			oddL = math.Round(oddL*100) / 100
-
-			if flags.Hockey {
-				return oddF, oddL, oddX
-			}
-
-			return oddF, oddX, oddL
-		}
-	}
-
-	if flags.Debug {
-		fmt.Printf("DEBUG: [generateOdds] fallback to default odds")
-	}
-
-	if flags.Hockey {
-		return 2, 4, 3.5
-	}
-
-	// Значения по умолчанию
-	return 2, 3.5, 4
+// GenerateRecords генерирует записи для событий со случайными коэффициентами.
+// Коэффициенты воспроизводимы: они определяются flags.Seed.
+func GenerateRecords(eventsFromOldest []string, flags Flags, strategy Strategy) []TrainerRecord {
+	return Simulate(eventsFromOldest, NewRandomOddsProvider(flags.Seed, flags), flags, strategy)
 }
 
-// GenerateRecords генерирует записи для событий
-func GenerateRecords(eventsFromOldest []string, flags Flags, strategy Strategy) []TrainerRecord {
+// Simulate прогоняет стратегию по событиям (от старых к новым), получая
+// коэффициенты каждого события от provider
+func Simulate(eventsFromOldest []string, provider OddsProvider, flags Flags, strategy Strategy) []TrainerRecord {
 	records := make([]TrainerRecord, len(eventsFromOldest))
-	detector := NewPatternDetector()
+	detector := NewPatternDetector(flags.EffectiveConfig())
+	detector.bus = flags.Bus
+	detector.strategy = strategy.Name()
+
+	flags.Bus.Debugf("Starting Simulate with %d events", len(eventsFromOldest))
+	flags.Bus.Debugf("Strategy: %s", strategy.Name())
 
 	// Начальная запись (предыдущая для первого события)
 	previous := TrainerRecord{
-		Result: "N",
-		Total:  0,
+		Result:   "N",
+		Total:    0,
+		Bankroll: flags.EffectiveConfig().Bankroll.Capital,
 	}
 
+	flags.Bus.Debugf("Initial previous record: Result=%s, Total=%.0f", previous.Result, previous.Total)
+
 	for i, event := range eventsFromOldest {
-		oddF, oddX, oddL := generateOdds(flags)
+		odds := provider.Odds(i + 1)
 
 		current := TrainerRecord{
 			EventNumber: i + 1,
 			Result:      event,
-			OddF:        oddF,
-			OddX:        oddX,
-			OddL:        oddL,
-		}
-
-		// Применяем стратегию
-		strategy.Calculate(&current, &previous, flags)
-
-		// Детектируем паттерны
-		detectedPatterns := detector.AddEvent(event, i+1, current)
-		if len(detectedPatterns) > 0 {
-			current.Pattern = strings.Join(detectedPatterns, "_")
-		}
-
-		records[i] = current
-		previous = current
-
-		if flags.Verbose {
-			fmt.Printf("Событие %d: %s, Ставки: F=%.0f X=%.0f L=%.0f, Total=%.0f\n",
-				i+1, event, current.BetF, current.BetX, current.BetL, current.Total)
-		}
-	}
-
-	return records
-}
-
-// ReadCSV читает CSV файл и возвращает записи
-func ReadCSV(filename string) ([]TrainerRecord, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, err
-	}
-
-	// Skip header if present
-	startIdx := 0
-	if len(records) > 0 && records[0][0] == "event_number" {
-		startIdx = 1
-	}
-
-	// Convert string records to TrainerRecord structs
-	trainerRecords := make([]TrainerRecord, 0, len(records)-startIdx)
-
-	for i := startIdx; i < len(records); i++ {
-		row := records[i]
-
-		// Ensure we have enough columns
-		if len(row) < 16 {
-			return nil, fmt.Errorf("invalid CSV format at row %d: expected at least 16 columns, got %d", i+1, len(row))
-		}
-
-		// Parse each field
-		eventNumber, err := strconv.Atoi(row[0])
-		if err != nil {
-			return nil, fmt.Errorf("error parsing event_number at row %d: %v", i+1, err)
-		}
-
-		oddF, err := strconv.ParseFloat(row[2], 64)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing oddF at row %d: %v", i+1, err)
-		}
-
-		oddX, err := strconv.ParseFloat(row[3], 64)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing oddX at row %d: %v", i+1, err)
-		}
-
-		oddL, err := strconv.ParseFloat(row[4], 64)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing oddL at row %d: %v", i+1, err)
-		}
-
-		betF, err := strconv.ParseFloat(row[5], 64)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing betF at row %d: %v", i+1, err)
-		}
-
-		betX, err := strconv.ParseFloat(row[6], 64)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing betX at row %d: %v", i+1, err)
+			OddF:        odds.OddF,
+			OddX:        odds.OddX,
+			OddL:        odds.OddL,
 		}
-
-		betL, err := strconv.ParseFloat(row[7], 64)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing betL at row %d: %v", i+1, err)
+		if matches, ok := provider.(MatchProvider); ok {
+			current.Match = matches.Match(i + 1)
 		}
 
-		lossF, err := strconv.ParseFloat(row[8], 64)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing lossF at row %d: %v", i+1, err)
-		}
+		flags.Bus.Debugf("Event %d: Before strategy calculation - Result=%s, Previous: UF=%.0f, UX=%.0f, UL=%.0f, LossF=%.0f, LossX=%.0f, LossL=%.0f, Total=%.0f",
+			i+1, event, previous.UF, previous.UX, previous.UL, previous.LossF, previous.LossX, previous.LossL, previous.Total)
 
-		lossX, err := strconv.ParseFloat(row[9], 64)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing lossX at row %d: %v", i+1, err)
-		}
-
-		lossL, err := strconv.ParseFloat(row[10], 64)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing lossL at row %d: %v", i+1, err)
-		}
-
-		total, err := strconv.ParseFloat(row[11], 64)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing total at row %d: %v", i+1, err)
+		// Принимаем решение стратегии и рассчитываем событие
+		applyStrategy(strategy, &current, &previous, flags)
+		if lines, ok := provider.(LineProvider); ok {
+			if baseline, ok := lines.Baseline(i + 1); ok {
+				current.LineValue = lineValue(current, baseline)
+			}
 		}
 
-		uf, err := strconv.ParseFloat(row[12], 64)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing uf at row %d: %v", i+1, err)
-		}
+		flags.Bus.Debugf("Event %d: After strategy calculation - BetF=%.0f, BetX=%.0f, BetL=%.0f, LossF=%.0f, LossX=%.0f, LossL=%.0f, Total=%.0f, UF=%.0f, UX=%.0f, UL=%.0f",
+			i+1, current.BetF, current.BetX, current.BetL, current.LossF, current.LossX, current.LossL, current.Total, current.UF, current.UX, current.UL)
 
-		ux, err := strconv.ParseFloat(row[13], 64)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing ux at row %d: %v", i+1, err)
-		}
+		// Детектируем паттерны
+		current.setPatterns(detector.AddEvent(event, i+1, current))
 
-		ul, err := strconv.ParseFloat(row[14], 64)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing ul at row %d: %v", i+1, err)
-		}
+		records[i] = current
+		previous = current
 
-		// Create TrainerRecord
-		record := TrainerRecord{
-			EventNumber: eventNumber,
-			Result:      row[1],
-			OddF:        oddF,
-			OddX:        oddX,
-			OddL:        oddL,
-			BetF:        betF,
-			BetX:        betX,
-			BetL:        betL,
-			LossF:       lossF,
-			LossX:       lossX,
-			LossL:       lossL,
-			Total:       total,
-			UF:          uf,
-			UX:          ux,
-			UL:          ul,
-			Pattern:     row[15], // Pattern is the last column
+		if current.Stopped() {
+			flags.Bus.Debugf("Event %d: stopped by limit %s", i+1, current.Limit)
+			records = records[:i+1]
+			break
 		}
 
-		trainerRecords = append(trainerRecords, record)
+		flags.Bus.Debugf("Event %d: Complete record - %s,%.2f,%.2f,%.2f,%.0f,%.0f,%.0f,%.0f,%.0f,%.0f,%.0f,%.0f,%.0f,%.0f",
+			i+1, current.Result, current.OddF, current.OddX, current.OddL,
+			current.BetF, current.BetX, current.BetL, current.LossF, current.LossX, current.LossL,
+			current.Total, current.UF, current.UX, current.UL)
 	}
 
-	return trainerRecords, nil
+	flags.Bus.Debugf("Simulate completed, generated %d records", len(records))
+
+	return records
 }
 
 // ReadInputFile читает и парсит .input файл
@@ -444,52 +195,6 @@ func ReadInputFile(filename string) ([]common.Event, error) {
 	return common.ReadInputFile(filename)
 }
 
-// SaveToCSV сохраняет записи в CSV файл
-func SaveToCSV(records []TrainerRecord, filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Заголовки
-	headers := []string{"event_number", "result", "oddF", "oddX", "oddL", "betF", "betX", "betL",
-		"lossF", "lossX", "lossL", "total", "uf", "ux", "ul", "pattern"}
-	if err := writer.Write(headers); err != nil {
-		return err
-	}
-
-	// Данные
-	for _, record := range records {
-		row := []string{
-			strconv.Itoa(record.EventNumber),
-			record.Result,
-			strconv.FormatFloat(record.OddF, 'f', 2, 64),
-			strconv.FormatFloat(record.OddX, 'f', 2, 64),
-			strconv.FormatFloat(record.OddL, 'f', 2, 64),
-			strconv.FormatFloat(record.BetF, 'f', 0, 64),
-			strconv.FormatFloat(record.BetX, 'f', 0, 64),
-			strconv.FormatFloat(record.BetL, 'f', 0, 64),
-			strconv.FormatFloat(record.LossF, 'f', 0, 64),
-			strconv.FormatFloat(record.LossX, 'f', 0, 64),
-			strconv.FormatFloat(record.LossL, 'f', 0, 64),
-			strconv.FormatFloat(record.Total, 'f', 0, 64),
-			strconv.FormatFloat(record.UF, 'f', 0, 64),
-			strconv.FormatFloat(record.UX, 'f', 0, 64),
-			strconv.FormatFloat(record.UL, 'f', 0, 64),
-			record.Pattern,
-		}
-		if err := writer.Write(row); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 // CalculateStats вычисляет статистику
 func CalculateStats(records []TrainerRecord, eventsFromOldest []string) Stats {
 	stats := Stats{
@@ -541,6 +246,50 @@ func CalculateStats(records []TrainerRecord, eventsFromOldest []string) Stats {
 		}
 	}
 
+	// Банк (только при заданном начальном банке) и лимиты, которые
+	// срабатывают и без него (max_bet, max_stake)
+	lastBankrollEvent := 0
+	for _, record := range records {
+		if record.Limit != "" {
+			stats.HasLimits = true
+		}
+		if record.Bankroll != 0 {
+			if !stats.HasBankroll || record.Bankroll < stats.MinBankroll {
+				stats.MinBankroll = record.Bankroll
+			}
+			stats.HasBankroll = true
+			if record.EventNumber > lastBankrollEvent {
+				lastBankrollEvent = record.EventNumber
+				stats.FinalBankroll = record.Bankroll
+			}
+		}
+		switch record.Limit {
+		case OnCapClamp, OnCapWriteOff:
+			stats.CapHits++
+		case OnCapStop:
+			stats.StoppedAt = record.EventNumber
+		case LimitRuin:
+			stats.RuinedAt = record.EventNumber
+		}
+	}
+
+	stats.PatternCounts = PatternCounts(records)
+	stats.ReactionCounts = map[string]int{}
+	for _, record := range records {
+		if record.Reaction != "" {
+			stats.ReactionCounts[record.Reaction]++
+		}
+	}
+
+	// Оборот по букмекерам и выигрыш от выбора линии
+	stats.Turnover = BookmakerTurnover(records)
+	for _, record := range records {
+		stats.LineValue += record.LineValue
+	}
+
+	// Капитал и просадки
+	stats.Drawdown = CalculateDrawdown(EquityCurve(records))
+
 	// Максимальные серии
 	currentStreaks := map[string]int{"F": 0, "X": 0, "L": 0}
 	notFStreak := 0
@@ -580,6 +329,20 @@ func CalculateStats(records []TrainerRecord, eventsFromOldest []string) Stats {
 	return stats
 }
 
+// PatternCounts подсчитывает количество событий с каждым паттерном
+func PatternCounts(records []TrainerRecord) map[string]int {
+	counts := map[string]int{}
+	for _, record := range records {
+		if record.Pattern == "" {
+			continue
+		}
+		for _, pattern := range strings.Split(record.Pattern, "_") {
+			counts[pattern]++
+		}
+	}
+	return counts
+}
+
 // PrintReport выводит отчет
 func PrintReport(stats Stats, records []TrainerRecord) {
 	fmt.Println("\n" + strings.Repeat("=", 60))
@@ -607,92 +370,108 @@ func PrintReport(stats Stats, records []TrainerRecord) {
 	fmt.Printf("   L: %d\n", stats.MaxStreaks["L"])
 	fmt.Printf("   Не-F: %d\n", stats.MaxStreaks["notF"])
 
-	fmt.Printf("   Всего записей: %d\n", stats.TotalRecords)
-	if len(records) > 0 {
-		fmt.Printf("   Итоговый результат: %.0f\n", records[0].Total)
-	}
-}
-
-// GenerateRecordsWithOdds генерирует записи для событий с заданными коэффициентами
-func GenerateRecordsWithOdds(eventsFromOldest []string, odds []struct{ OddF, OddX, OddL float64 }, flags Flags, strategy Strategy) []TrainerRecord {
-	records := make([]TrainerRecord, len(eventsFromOldest))
-	detector := NewPatternDetector()
-
-	if flags.Debug {
-		fmt.Printf("DEBUG: Starting GenerateRecordsWithOdds with %d events\n", len(eventsFromOldest))
-		fmt.Printf("DEBUG: Strategy: %s\n", strategy.Name())
-	}
-
-	// Начальная запись (предыдущая для первого события)
-	previous := TrainerRecord{
-		Result: "N",
-		Total:  0,
+	if stats.HasBankroll || stats.HasLimits {
+		fmt.Printf("\n🏦 БАНК:\n")
+		if stats.HasBankroll {
+			fmt.Printf("   Итоговый банк: %.0f\n", stats.FinalBankroll)
+			fmt.Printf("   Минимальный банк: %.0f\n", stats.MinBankroll)
+		}
+		fmt.Printf("   Урезанных ставок: %d\n", stats.CapHits)
+		if stats.RuinedAt > 0 {
+			fmt.Printf("   💥 Разорение на событии %d: банка не хватает на ставки\n", stats.RuinedAt)
+		} else if stats.StoppedAt > 0 {
+			fmt.Printf("   ⛔ Расчет остановлен на событии %d: превышен лимит ставок\n", stats.StoppedAt)
+		}
 	}
 
-	if flags.Debug {
-		fmt.Printf("DEBUG: Initial previous record: Result=%s, Total=%.0f\n", previous.Result, previous.Total)
+	if len(stats.PatternCounts) > 0 {
+		names := make([]string, 0, len(stats.PatternCounts))
+		for name := range stats.PatternCounts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Printf("\n⚠️ ПАТТЕРНЫ (событий):\n")
+		for _, name := range names {
+			fmt.Printf("   %s: %d (%.1f%%)\n", name, stats.PatternCounts[name],
+				float64(stats.PatternCounts[name])/float64(stats.TotalRecords)*100)
+		}
 	}
 
-	for i, event := range eventsFromOldest {
-		var oddF, oddX, oddL float64
-		if i < len(odds) {
-			oddF = odds[i].OddF
-			oddX = odds[i].OddX
-			oddL = odds[i].OddL
-			if flags.Debug {
-				fmt.Printf("DEBUG: Event %d: Using provided odds - F=%.2f, X=%.2f, L=%.2f\n", i+1, oddF, oddX, oddL)
-			}
-		} else {
-			oddF, oddX, oddL = generateOdds(flags)
-			if flags.Debug {
-				fmt.Printf("DEBUG: Event %d: Generated odds - F=%.2f, X=%.2f, L=%.2f\n", i+1, oddF, oddX, oddL)
-			}
+	if len(stats.ReactionCounts) > 0 {
+		names := make([]string, 0, len(stats.ReactionCounts))
+		for name := range stats.ReactionCounts {
+			names = append(names, name)
 		}
-
-		current := TrainerRecord{
-			EventNumber: i + 1,
-			Result:      event,
-			OddF:        oddF,
-			OddX:        oddX,
-			OddL:        oddL,
+		sort.Strings(names)
+		fmt.Printf("\n🛡️ РЕАКЦИИ НА ПАТТЕРНЫ:\n")
+		for _, name := range names {
+			fmt.Printf("   %s: %d\n", name, stats.ReactionCounts[name])
 		}
+	}
 
-		if flags.Debug {
-			fmt.Printf("DEBUG: Event %d: Before strategy calculation - Result=%s, Previous: UF=%.0f, UX=%.0f, UL=%.0f, LossF=%.0f, LossX=%.0f, LossL=%.0f, Total=%.0f\n",
-				i+1, event, previous.UF, previous.UX, previous.UL, previous.LossF, previous.LossX, previous.LossL, previous.Total)
+	if len(stats.Turnover) > 0 {
+		names := make([]string, 0, len(stats.Turnover))
+		total := 0.0
+		for name, turnover := range stats.Turnover {
+			names = append(names, name)
+			total += turnover
 		}
-
-		// Применяем стратегию
-		strategy.Calculate(&current, &previous, flags)
-
-		if flags.Debug {
-			fmt.Printf("DEBUG: Event %d: After strategy calculation - BetF=%.0f, BetX=%.0f, BetL=%.0f, LossF=%.0f, LossX=%.0f, LossL=%.0f, Total=%.0f, UF=%.0f, UX=%.0f, UL=%.0f\n",
-				i+1, current.BetF, current.BetX, current.BetL, current.LossF, current.LossX, current.LossL, current.Total, current.UF, current.UX, current.UL)
+		sort.Strings(names)
+		fmt.Printf("\n🏦 БУКМЕКЕРЫ:\n")
+		for _, name := range names {
+			fmt.Printf("   %s: оборот %.0f (%.1f%%)\n", name, stats.Turnover[name], stats.Turnover[name]/total*100)
 		}
+		fmt.Printf("   Выигрыш от выбора линии: %.0f\n", stats.LineValue)
+	}
 
-		// Детектируем паттерны
-		detectedPatterns := detector.AddEvent(event, i+1, current)
-		if len(detectedPatterns) > 0 {
-			current.Pattern = strings.Join(detectedPatterns, "_")
-			if flags.Debug {
-				fmt.Printf("DEBUG: Event %d: Pattern detected - %s\n", i+1, current.Pattern)
-			}
+	if stats.TotalRecords > 0 {
+		dd := stats.Drawdown
+		fmt.Printf("\n💹 КАПИТАЛ (итог минус неотыгранные убытки и ставки):\n")
+		fmt.Printf("   Итоговый капитал: %.0f\n", dd.FinalEquity)
+		fmt.Printf("   Минимальный капитал: %.0f\n", dd.MinEquity)
+		fmt.Printf("   Максимальная просадка: %.0f (событие %d)\n", dd.MaxDrawdown, dd.MaxDrawdownAt)
+		fmt.Printf("   Событий под водой: %d (%.1f%%)\n", dd.UnderwaterEvents,
+			float64(dd.UnderwaterEvents)/float64(stats.TotalRecords)*100)
+		fmt.Printf("   Самое долгое восстановление: %d событий\n", dd.LongestRecovery)
+		if dd.Unrecovered > 0 {
+			fmt.Printf("   Не восстановлено: %d событий под водой на конец расчета\n", dd.Unrecovered)
 		}
+	}
 
-		records[i] = current
-		previous = current
+	fmt.Printf("   Всего записей: %d\n", stats.TotalRecords)
+	if len(records) > 0 {
+		fmt.Printf("   Итоговый результат: %.0f\n", records[0].Total)
+	}
+}
 
-		if flags.Debug {
-			fmt.Printf("DEBUG: Event %d: Complete record - %s,%.2f,%.2f,%.2f,%.0f,%.0f,%.0f,%.0f,%.0f,%.0f,%.0f,%.0f,%.0f,%.0f\n",
-				i+1, current.Result, current.OddF, current.OddX, current.OddL,
-				current.BetF, current.BetX, current.BetL, current.LossF, current.LossX, current.LossL,
-				current.Total, current.UF, current.UX, current.UL)
-		}
+// GenerateRecordsWithOdds генерирует записи для событий с заданными коэффициентами.
+// Для событий без заданных коэффициентов они генерируются по flags.Seed.
+func GenerateRecordsWithOdds(eventsFromOldest []string, odds []struct{ OddF, OddX, OddL float64 }, flags Flags, strategy Strategy) []TrainerRecord {
+	fixed := make([]Odds, len(odds))
+	for i, o := range odds {
+		fixed[i] = Odds(o)
 	}
+	provider := NewFixedOddsProvider(fixed, NewRandomOddsProvider(flags.Seed, flags), flags)
+	return Simulate(eventsFromOldest, provider, flags, strategy)
+}
 
-	if flags.Debug {
-		fmt.Printf("DEBUG: GenerateRecordsWithOdds completed, generated %d records\n", len(records))
+// GenerateRecordsFromEvents генерирует записи для событий .input файла, как
+// GenerateRecordsWithOdds, и переносит в записи метаданные матчей
+func GenerateRecordsFromEvents(events []common.Event, flags Flags, strategy Strategy) []TrainerRecord {
+	eventsFromOldest := make([]string, len(events))
+	for i, event := range events {
+		eventsFromOldest[i] = event.Result
 	}
+	provider := NewInputOddsProvider(events, NewRandomOddsProvider(flags.Seed, flags), flags)
+	return Simulate(eventsFromOldest, provider, flags, strategy)
+}
 
-	return records
+// HasMatchData сообщает, есть ли в записях метаданные матчей
+func HasMatchData(records []TrainerRecord) bool {
+	for _, record := range records {
+		if !record.Match.IsZero() {
+			return true
+		}
+	}
+	return false
 }
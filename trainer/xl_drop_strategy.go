@@ -1,7 +1,5 @@
 package trainer
 
-import "fmt"
-
 // XLDropStrategy реализует стратегию "Ставка с ограниченной поддержкой"
 type XLDropStrategy struct{}
 
@@ -13,12 +11,54 @@ func (s *XLDropStrategy) Description() string {
     return "Стратегия 'Ставка с ограниченной поддержкой' с пессимизацией страховки"
 }
 
-func (s *XLDropStrategy) Calculate(current, previous *TrainerRecord, flags Flags) {
-    if flags.Debug {
-        fmt.Printf("\n=== DEBUG: Calculate with strategy %s ===\n", s.Name())
+// Version меняется при изменении расчета ставок: .actual файлы пересчитываются
+func (s *XLDropStrategy) Version() string {
+    return "1"
+}
+
+func (s *XLDropStrategy) Params() []Param {
+    return []Param{
+        {Name: "ratio", Type: ParamFloat, Default: 0.3, Min: 0, Max: 1,
+            Description: "Доля перенесенного убытка на X (в хоккее на L), остальное - на другой исход"},
+        {Name: "deferX", Type: ParamInt, Default: 5, Min: 1, Max: 1000,
+            Description: "Серия без X, начиная с которой на X ставится только базовая сумма, а убыток откладывается"},
+        {Name: "deferL", Type: ParamInt, Default: 6, Min: 1, Max: 1000,
+            Description: "Серия без L, начиная с которой на L ставится только базовая сумма, а убыток откладывается"},
+        {Name: "redWriteOff", Type: ParamFloat, Default: 0.5, Min: 0, Max: 1,
+            Description: "Доля убытка, списываемая из итога после паттерна RED"},
     }
+}
 
-    eventNumber := current.EventNumber
+// DefaultReactions после RED списывает из итога долю redWriteOff убытка
+func (s *XLDropStrategy) DefaultReactions(params Params) map[string]Reaction {
+    return map[string]Reaction{
+        "RED": {Policy: "writeoff", Fraction: params["redWriteOff"]},
+    }
+}
+
+func (s *XLDropStrategy) TraceFields() []TraceField {
+    return []TraceField{
+        {Name: "realLoss", Description: "Убыток сверх трех базовых ставок"},
+        {Name: "writeOff", Description: "Списано из итога реакцией на паттерн"},
+        {Name: "moveTo", Description: "Исход, на который реакция перенесла весь убыток"},
+        {Name: "carriedLoss", Description: "Убыток, распределяемый по X и L"},
+        {Name: "smallPart", Description: "Доля ratio убытка (на X, в хоккее на L)"},
+        {Name: "bigPart", Description: "Остаток убытка (на L, в хоккее на X)"},
+        {Name: "lossF", Description: "Убыток, который должна покрыть ставка F"},
+        {Name: "lossX", Description: "Убыток, который должна покрыть ставка X"},
+        {Name: "lossL", Description: "Убыток, который должна покрыть ставка L"},
+        {Name: "stakeX", Description: "Серия без X достигла deferX: ставка X только на базовую сумму"},
+        {Name: "deferredX", Description: "Отложенный убыток X, остающийся после выигрыша X"},
+        {Name: "stakeL", Description: "Серия без L достигла deferL: ставка L только на базовую сумму"},
+        {Name: "deferredL", Description: "Отложенный убыток L, остающийся после выигрыша L"},
+        {Name: "paused", Description: "Пауза реакции на паттерн: событий без ставок осталось после текущего"},
+    }
+}
+
+func (s *XLDropStrategy) Decide(previous *TrainerRecord, odds Odds, flags Flags) BetDecision {
+    flags.Bus.Debugf("=== Calculate with strategy %s ===", s.Name())
+
+    eventNumber := previous.EventNumber + 1
 
     lossF := previous.LossF
     lossX := previous.LossX
@@ -27,9 +67,10 @@ func (s *XLDropStrategy) Calculate(current, previous *TrainerRecord, flags Flags
     uf := previous.UF
     ux := previous.UX
     ul := previous.UL
-    pattern := previous.Pattern
 
-    baseAmount := config.DefaultBetF
+    cfg := flags.EffectiveConfig()
+    params := cfg.StrategyParams(s)
+    baseAmount := cfg.DefaultBetF
 
     // Инициализация потерь
     if uf == 0 {
@@ -42,60 +83,57 @@ func (s *XLDropStrategy) Calculate(current, previous *TrainerRecord, flags Flags
         lossL = baseAmount
     }
 
-    deferLoss := map[string]bool{
-        "F": false,
-        "X": false,
-        "L": false,
-    }
-
-    if flags.Debug {
-        fmt.Printf("DEBUG: INITIALIZE: Event %d: lossF: %.0f, lossX: %.0f, lossL: %.0f\n", eventNumber, lossF, lossX, lossL)
-    }
+    flags.Bus.Debugf("INITIALIZE: Event %d: lossF: %.0f, lossX: %.0f, lossL: %.0f", eventNumber, lossF, lossX, lossL)
 
+    reaction := ""
+    trace := flags.NewTrace()
     if uf > 0 || ux > 0 || ul > 0 {
         realLoss := lossF + lossX + lossL - baseAmount*3
+        trace.Add("realLoss", realLoss)
         lossF = baseAmount
         lossX = baseAmount
         lossL = baseAmount
 
-        if flags.Debug {
-            fmt.Printf("DEBUG: Event %d: lossF: %.0f, lossX: %.0f, lossL: %.0f\n", eventNumber, lossF, lossX, lossL)
-            fmt.Printf("DEBUG: Event %d: realLoss BEFORE patterns %.0f\n", eventNumber, realLoss)
-        }
+        flags.Bus.Debugf("Event %d: lossF: %.0f, lossX: %.0f, lossL: %.0f", eventNumber, lossF, lossX, lossL)
+        flags.Bus.Debugf("Event %d: realLoss BEFORE patterns %.0f", eventNumber, realLoss)
 
-        // GREEN: метрика > 50,000
-        // YELLOW: 2+ метрики > 50,000 ИЛИ любая > 100,000
-        // RED: 3+ метрики > 100,000 (катастрофа)
-        if pattern == "RED" {
-            halfPart := roundUp(realLoss / 2)
-            total -= halfPart
-            realLoss = halfPart
-        } else if pattern == "YELLOW" {
-            // if flags.Hockey {
-            //  halfPart := roundUp(realLoss / 2)
-            //  total -= halfPart
-            //  realLoss = halfPart
-            // }
-        } else if pattern == "GREEN" {
-            // total -= realLoss
-            // realLoss = 0
-            // partLoss := roundUp(realLoss / 2)
-            // total -= partLoss
-            // realLoss -= partLoss
+        // Реакция на паттерны предыдущего события задается политиками
+        // (DefaultReactions, переопределяются в reactions конфигурации)
+        r := React(s, previous, realLoss, total, cfg)
+        if r.Pause > 0 {
+            return pausedDecision(s.Name(), previous, r.Pause-1, r.Applied, flags)
         }
-
-        if flags.Debug {
-            fmt.Printf("DEBUG: Event %d: realLoss AFTER patterns %.0f\n", eventNumber, realLoss)
+        if written := total - r.Total; written > 0 {
+            trace.Add("writeOff", written)
+        }
+        realLoss = r.RealLoss
+        total = r.Total
+        reaction = r.Applied
+
+        if r.Target != "" && realLoss > 0 {
+            trace.AddText("moveTo", r.Target)
+            switch r.Target {
+            case "F":
+                lossF += realLoss
+            case "X":
+                lossX += realLoss
+            case "L":
+                lossL += realLoss
+            }
+            realLoss = 0
         }
 
+        flags.Bus.Debugf("Event %d: realLoss AFTER patterns %.0f", eventNumber, realLoss)
+
         if realLoss > 0 {
-            ratio := 0.3
-            smallPart := roundUp(ratio * realLoss)
-            bigPart := roundUp(realLoss - smallPart)
+            ratio := params["ratio"]
+            smallPart := cfg.roundUp(ratio * realLoss)
+            bigPart := cfg.roundUp(realLoss - smallPart)
+            trace.Add("carriedLoss", realLoss)
+            trace.Add("smallPart", smallPart)
+            trace.Add("bigPart", bigPart)
 
-            if flags.Debug {
-                fmt.Printf("DEBUG: Event %d: ratio: %.2f, smallPart: %.0f, bigPart: %.0f\n", eventNumber, ratio, smallPart, bigPart)
-            }
+            flags.Bus.Debugf("Event %d: ratio: %.2f, smallPart: %.0f, bigPart: %.0f", eventNumber, ratio, smallPart, bigPart)
 
             if flags.Hockey {
                 lossX += bigPart
@@ -107,97 +145,42 @@ func (s *XLDropStrategy) Calculate(current, previous *TrainerRecord, flags Flags
         }
     }
 
-    betF := calcBet(lossF, current.OddF)
-
-    betX := calcBet(lossX, current.OddX)
-    if ux >= 5 {
-        betX = calcBet(baseAmount, current.OddX)
-        deferLoss["X"] = true
+    decision := BetDecision{
+        Strategy: s.Name(),
+        Losses:   PerOutcome{F: lossF, X: lossX, L: lossL},
+        Total:    total,
+        Profit:   baseAmount,
+        Reaction: reaction,
     }
-
-    betL := calcBet(lossL, current.OddL)
-    if ul >= 6 {
-        betL = calcBet(baseAmount, current.OddL)
-        deferLoss["L"] = true
+    trace.Add("lossF", lossF)
+    trace.Add("lossX", lossX)
+    trace.Add("lossL", lossL)
+
+    decision.Bets.F = cfg.calcBet(lossF, odds.OddF)
+
+    // Отложенный убыток: ставим только базовую сумму, а при выигрыше
+    // списываем из убытка лишь ее
+    decision.Bets.X = cfg.calcBet(lossX, odds.OddX)
+    if ux >= params["deferX"] {
+        decision.Bets.X = cfg.calcBet(baseAmount, odds.OddX)
+        decision.Residual.X = lossX - baseAmount
+        trace.Add("stakeX", baseAmount)
+        trace.Add("deferredX", decision.Residual.X)
     }
 
-    if flags.Debug {
-        fmt.Printf("DEBUG: Event %d: deferLoss_X: %v, deferLoss_L: %v\n", eventNumber, deferLoss["X"], deferLoss["L"])
-        fmt.Printf("DEBUG: Event %d: lossF: %.0f, lossX: %.0f, lossL: %.0f\n", eventNumber, lossF, lossX, lossL)
-        fmt.Printf("DEBUG: Event %d: betF: %.0f, betX: %.0f, betL: %.0f\n", eventNumber, betF, betX, betL)
-        fmt.Printf("DEBUG: Event %d: total BEFORE process %.0f\n", eventNumber, total)
-        fmt.Printf("DEBUG: Event %d: RESULT %s\n", eventNumber, current.Result)
+    decision.Bets.L = cfg.calcBet(lossL, odds.OddL)
+    if ul >= params["deferL"] {
+        decision.Bets.L = cfg.calcBet(baseAmount, odds.OddL)
+        decision.Residual.L = lossL - baseAmount
+        trace.Add("stakeL", baseAmount)
+        trace.Add("deferredL", decision.Residual.L)
     }
+    decision.Trace = trace.Entries()
 
-    // Обработка результата
-    if current.Result == "F" {
-        // Серии
-        uf = 0
-        ux++
-        ul++
-        // Потери
-        lossF = 0
-        lossX += betX
-        lossL += betL
-        total += baseAmount
-    }
-    if current.Result == "X" {
-        // Серии
-        uf++
-        ux = 0
-        ul++
-        // Потери
-        lossF += betF
-        lossL += betL
-        if deferLoss["X"] {
-            lossX -= baseAmount
-        } else {
-            lossX = 0
-        }
-        total += baseAmount
-    }
-    if current.Result == "L" {
-        // Серии
-        uf++
-        ux++
-        ul = 0
-        // Потери
-        lossF += betF
-        lossX += betX
-        if deferLoss["L"] {
-            lossL -= baseAmount
-        } else {
-            lossL = 0
-        }
-        total += baseAmount
-    }
-    if current.Result == "N" && flags.Real {
-        // Серии
-        uf = -1
-        ux = -1
-        ul = -1
-        // Потери
-        lossF = -1
-        lossX = -1
-        lossL = -1
-    } else if current.Result == "N" && !flags.Testing {
-        panic("current.Result N w/o flags.Real")
-    }
-
-    if flags.Debug {
-        fmt.Printf("DEBUG: Event %d: total FINAL %.0f\n", eventNumber, total)
-        fmt.Printf("DEBUG: Event %d: END GAME: lossF: %.0f, lossX: %.0f, lossL: %.0f\n", eventNumber, lossF, lossX, lossL)
-    }
+    flags.Bus.Debugf("Event %d: deferLoss_X: %v, deferLoss_L: %v", eventNumber, ux >= params["deferX"], ul >= params["deferL"])
+    flags.Bus.Debugf("Event %d: lossF: %.0f, lossX: %.0f, lossL: %.0f", eventNumber, lossF, lossX, lossL)
+    flags.Bus.Debugf("Event %d: betF: %.0f, betX: %.0f, betL: %.0f", eventNumber, decision.Bets.F, decision.Bets.X, decision.Bets.L)
+    flags.Bus.Debugf("Event %d: total BEFORE process %.0f", eventNumber, total)
 
-    // Обновляем текущую запись
-    current.BetF = betF
-    current.BetX = betX
-    current.BetL = betL
-    current.LossF = lossF
-    current.LossX = lossX
-    current.LossL = lossL
-    current.Total = total
-    current.UF = uf
-    current.UX = ux
-    current.UL = ul
+    return decision
 }
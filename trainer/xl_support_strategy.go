@@ -11,7 +11,49 @@ func (s *XLWithSupportStrategy) Description() string {
 	return "Стратегия 'Ставка с поддержкой' с распределением убытков"
 }
 
-func (s *XLWithSupportStrategy) Calculate(current, previous *TrainerRecord, flags Flags) {
+// Version меняется при изменении расчета ставок: .actual файлы пересчитываются
+func (s *XLWithSupportStrategy) Version() string {
+	return "1"
+}
+
+func (s *XLWithSupportStrategy) Params() []Param {
+	return []Param{
+		{Name: "ratio", Type: ParamFloat, Default: 0.3, Min: 0, Max: 1,
+			Description: "Доля перенесенного убытка на X, остальное - на L"},
+		{Name: "writeOff", Type: ParamFloat, Default: 1, Min: 0, Max: 1,
+			Description: "Доля убытка, списываемая из итога после любого паттерна"},
+		{Name: "partialMult", Type: ParamFloat, Default: PARTIAL_COVERAGE_MULT, Min: 0, Max: 100,
+			Description: "Непокрытая часть ставки L в базовых ставках при частичном покрытии"},
+	}
+}
+
+// DefaultReactions после любого встроенного порогового паттерна списывает
+// из итога долю writeOff убытка
+func (s *XLWithSupportStrategy) DefaultReactions(params Params) map[string]Reaction {
+	writeOff := Reaction{Policy: "writeoff", Fraction: params["writeOff"]}
+	return map[string]Reaction{"RED": writeOff, "YELLOW": writeOff, "GREEN": writeOff}
+}
+
+func (s *XLWithSupportStrategy) TraceFields() []TraceField {
+	return []TraceField{
+		{Name: "realLoss", Description: "Убыток сверх трех базовых ставок"},
+		{Name: "writeOff", Description: "Списано из итога реакцией на паттерн"},
+		{Name: "moveTo", Description: "Исход, на который реакция перенесла весь убыток"},
+		{Name: "carriedLoss", Description: "Убыток, распределяемый по X и L"},
+		{Name: "smallPart", Description: "Доля ratio убытка на X"},
+		{Name: "bigPart", Description: "Остаток убытка на L"},
+		{Name: "fullCoverage", Description: "Исход, ставку которого выигрыш F возвращает полностью"},
+		{Name: "partialCoverage", Description: "Исход, ставку которого выигрыш F возвращает без partialMult базовых ставок"},
+		{Name: "coverageX", Description: "Часть ставки X, добавленная к убытку F"},
+		{Name: "coverageL", Description: "Часть ставки L, добавленная к убытку F"},
+		{Name: "lossF", Description: "Убыток, который должна покрыть ставка F (с покрытием)"},
+		{Name: "lossX", Description: "Убыток, который должна покрыть ставка X"},
+		{Name: "lossL", Description: "Убыток, который должна покрыть ставка L"},
+		{Name: "paused", Description: "Пауза реакции на паттерн: событий без ставок осталось после текущего"},
+	}
+}
+
+func (s *XLWithSupportStrategy) Decide(previous *TrainerRecord, odds Odds, flags Flags) BetDecision {
 	lossF := previous.LossF
 	lossX := previous.LossX
 	lossL := previous.LossL
@@ -19,9 +61,10 @@ func (s *XLWithSupportStrategy) Calculate(current, previous *TrainerRecord, flag
 	uf := previous.UF
 	ux := previous.UX
 	ul := previous.UL
-	pattern := previous.Pattern
 
-	baseAmount := config.DefaultBetF
+	cfg := flags.EffectiveConfig()
+	params := cfg.StrategyParams(s)
+	baseAmount := cfg.DefaultBetF
 
 	// Инициализация потерь
 	if uf == 0 {
@@ -36,118 +79,109 @@ func (s *XLWithSupportStrategy) Calculate(current, previous *TrainerRecord, flag
 
 	fullCoverage := ""
 	partialCoverage := ""
+	reaction := ""
+	trace := flags.NewTrace()
 
 	if uf > 0 || ux > 0 || ul > 0 {
 		realLoss := lossF + lossX + lossL - baseAmount*3
+		trace.Add("realLoss", realLoss)
 		lossF = baseAmount
 		lossX = baseAmount
 		lossL = baseAmount
 
-		// 🔸 Желтый: метрика > 50,000
-		// 🔴 Красный: 2+ метрики > 50,000 ИЛИ любая > 100,000
-		// ⚫ Черный: 3+ метрики > 100,000 (катастрофа)
-		if pattern == "RED" {
-			total -= realLoss
-			realLoss = 0
-		} else if pattern == "YELLOW" {
-			total -= realLoss
-			realLoss = 0
-		} else if pattern == "GREEN" {
-			total -= realLoss
+		// Реакция на паттерны предыдущего события задается политиками
+		// (DefaultReactions, переопределяются в reactions конфигурации)
+		r := React(s, previous, realLoss, total, cfg)
+		if r.Pause > 0 {
+			return pausedDecision(s.Name(), previous, r.Pause-1, r.Applied, flags)
+		}
+		if written := total - r.Total; written > 0 {
+			trace.Add("writeOff", written)
+		}
+		realLoss = r.RealLoss
+		total = r.Total
+		reaction = r.Applied
+
+		if r.Target != "" && realLoss > 0 {
+			trace.AddText("moveTo", r.Target)
+			switch r.Target {
+			case "F":
+				lossF += realLoss
+			case "X":
+				lossX += realLoss
+			case "L":
+				lossL += realLoss
+			}
 			realLoss = 0
 		}
 		if realLoss > 0 {
-			ratio := 0.3
-			smallPart := roundUp(ratio * realLoss)
+			ratio := params["ratio"]
+			smallPart := cfg.roundUp(ratio * realLoss)
+			bigPart := cfg.roundUp(realLoss - smallPart)
+			trace.Add("carriedLoss", realLoss)
+			trace.Add("smallPart", smallPart)
+			trace.Add("bigPart", bigPart)
 			lossX += smallPart
-			lossL += roundUp(realLoss - smallPart)
+			lossL += bigPart
 			fullCoverage = "X"
-			if lossL > baseAmount*PARTIAL_COVERAGE_MULT {
+			if lossL > baseAmount*params["partialMult"] {
 				partialCoverage = "L"
 			}
 		}
 	}
 
-	betX := calcBet(lossX, current.OddX)
-	betL := calcBet(lossL, current.OddL)
+	betX := cfg.calcBet(lossX, odds.OddX)
+	betL := cfg.calcBet(lossL, odds.OddL)
 
-	// Корректировка lossF в зависимости от покрытия
+	// Покрытие: выигрыш F возвращает ставку покрытого исхода полностью,
+	// а частично покрытого - за вычетом базовой суммы
+	var coverage PerOutcome
 	if fullCoverage == "XL" {
-		lossF += betX + betL
+		coverage.X = betX
+		coverage.L = betL
 	} else if fullCoverage == "X" {
-		lossF += betX
+		coverage.X = betX
 		if partialCoverage == "L" {
-			lossF += betL - baseAmount*PARTIAL_COVERAGE_MULT
+			coverage.L = betL - baseAmount*params["partialMult"]
 		}
 	} else if fullCoverage == "L" {
-		lossF += betL
+		coverage.L = betL
 		if partialCoverage == "X" {
-			lossF += betX - baseAmount*PARTIAL_COVERAGE_MULT
+			coverage.X = betX - baseAmount*params["partialMult"]
 		}
 	}
 
-	betF := calcBet(lossF, current.OddF)
-
-	// Обработка результата
-	if current.Result == "F" {
-		// Серии
-		uf = 0
-		ux++
-		ul++
-		// Потери
-		lossF = 0
-		if fullCoverage == "XL" {
-			// X L были покрыты полностью, убытки не растут
-		} else if fullCoverage == "X" {
-			// X был покрыт полностью, убытки не растут
-			// lossX остается прежним
-			if partialCoverage == "L" {
-				lossL += baseAmount * PARTIAL_COVERAGE_MULT
-			} else {
-				lossL += betL
-			}
-		} else if fullCoverage == "L" {
-			// L был покрыт полностью, убытки не растут
-			// lossL остается прежним
-			if partialCoverage == "X" {
-				lossX += baseAmount * PARTIAL_COVERAGE_MULT
-			} else {
-				lossX += betX
-			}
-		} else {
-			lossX += betX
-			lossL += betL
-		}
-	} else if current.Result == "X" {
-		// Серии
-		uf++
-		ux = 0
-		ul++
-		// Потери
-		lossF += betF
-		lossX = 0
-		lossL += betL
-	} else if current.Result == "L" {
-		// Серии
-		uf++
-		ux++
-		ul = 0
-		// Потери
-		lossF += betF
-		lossX += betX
-		lossL = 0
+	// Корректировка lossF в зависимости от покрытия
+	lossF += coverage.X + coverage.L
+
+	if fullCoverage != "" {
+		trace.AddText("fullCoverage", fullCoverage)
+	}
+	if partialCoverage != "" {
+		trace.AddText("partialCoverage", partialCoverage)
+	}
+	if coverage.X > 0 {
+		trace.Add("coverageX", coverage.X)
+	}
+	if coverage.L > 0 {
+		trace.Add("coverageL", coverage.L)
+	}
+	trace.Add("lossF", lossF)
+	trace.Add("lossX", lossX)
+	trace.Add("lossL", lossL)
+
+	betF := cfg.calcBet(lossF, odds.OddF)
+
+	return BetDecision{
+		Strategy: s.Name(),
+		Bets:     PerOutcome{F: betF, X: betX, L: betL},
+		Losses:   PerOutcome{F: lossF, X: lossX, L: lossL},
+		Coverage: coverage,
+		Support:  "F",
+		Total:    total,
+		Profit:   baseAmount,
+		Reaction: reaction,
+		Trace:    trace.Entries(),
+		Pending:  PendingCarry,
 	}
-	total += baseAmount
-
-	// Обновляем текущую запись
-	current.BetF = betF
-	current.BetX = betX
-	current.BetL = betL
-	current.LossF = lossF
-	current.LossX = lossX
-	current.LossL = lossL
-	current.Total = total
-	current.UF = uf
-	current.UX = ux
-	current.UL = ul
 }